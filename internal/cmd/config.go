@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/umlx5h/gtrash/internal/config"
+	"github.com/umlx5h/gtrash/internal/env"
+	"github.com/umlx5h/gtrash/internal/posix"
+	"github.com/umlx5h/gtrash/internal/xdg"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	// flag annotation: cannot be set in the config file (e.g. actions)
+	annotationNoConfig = "gtrash_no_config"
+	// flag annotation: environment variable which takes precedence over the config file
+	annotationEnv = "gtrash_env"
+	// flag annotation: default value before the config file is applied
+	annotationDefault = "gtrash_default"
+
+	// set by cobra's MarkFlagsMutuallyExclusive
+	annotationMutuallyExclusive = "cobra_annotation_mutually_exclusive"
+)
+
+// Mark flags which cannot be set in the config file
+func noConfig(fs *pflag.FlagSet, names ...string) {
+	for _, n := range names {
+		if err := fs.SetAnnotation(n, annotationNoConfig, []string{"true"}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Mark the flag whose default value is given by the environment variable
+func envFlag(fs *pflag.FlagSet, name string, key string) {
+	if err := fs.SetAnnotation(name, annotationEnv, []string{key}); err != nil {
+		panic(err)
+	}
+}
+
+func configurable(f *pflag.Flag) bool {
+	if f.Name == "help" {
+		return false
+	}
+	_, ok := f.Annotations[annotationNoConfig]
+	return !ok
+}
+
+// Set default values of flags in subcommands from the config file
+// Flags are not marked as changed, so command-line options still override them.
+func applyConfig(root *cobra.Command, cfg *config.Config) error {
+	for _, name := range cfg.CommandNames() {
+		var sub *cobra.Command
+		for _, c := range root.Commands() {
+			if c.Name() == name {
+				sub = c
+				break
+			}
+		}
+		if sub == nil {
+			return fmt.Errorf("config: [%s] unknown command", name)
+		}
+
+		keys := maps.Keys(cfg.Commands[name])
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			v := cfg.Commands[name][key]
+			f := sub.Flags().Lookup(key)
+			if f == nil || !configurable(f) {
+				return fmt.Errorf("config: [%s] unknown option %q", name, key)
+			}
+
+			if envs, ok := f.Annotations[annotationEnv]; ok {
+				if _, ok := os.LookupEnv(envs[0]); ok {
+					// environment variable is preferred
+					continue
+				}
+			}
+
+			// array for flags which can be specified multiple times
+			if list, ok := v.([]any); ok {
+				sv, ok := f.Value.(pflag.SliceValue)
+				if !ok {
+					return fmt.Errorf("config: [%s] %s: array is not supported", name, key)
+				}
+				values := make([]string, len(list))
+				for i, e := range list {
+					s, ok := e.(string)
+					if !ok {
+						return fmt.Errorf("config: [%s] %s: array must contain only strings", name, key)
+					}
+					values[i] = s
+				}
+				if err := sv.Replace(values); err != nil {
+					return fmt.Errorf("config: [%s] %s: invalid value %q: %w", name, key, values, err)
+				}
+				setConfigDefault(sub.Flags(), f)
+				continue
+			}
+
+			var value string
+			switch v := v.(type) {
+			case string:
+				value = v
+			case bool:
+				value = strconv.FormatBool(v)
+			case int64:
+				value = strconv.FormatInt(v, 10)
+			default:
+				return fmt.Errorf("config: [%s] %s: unsupported value type %T", name, key, v)
+			}
+
+			if err := f.Value.Set(value); err != nil {
+				return fmt.Errorf("config: [%s] %s: invalid value %q: %w", name, key, value, err)
+			}
+			setConfigDefault(sub.Flags(), f)
+		}
+	}
+
+	return nil
+}
+
+// Replace the default value shown in help, the original one is kept to check mutually exclusive flags
+func setConfigDefault(fs *pflag.FlagSet, f *pflag.Flag) {
+	if _, ok := f.Annotations[annotationDefault]; !ok {
+		if err := fs.SetAnnotation(f.Name, annotationDefault, []string{f.DefValue}); err != nil {
+			panic(err)
+		}
+	}
+	f.DefValue = f.Value.String()
+}
+
+// Built-in default value before the config file is applied
+func builtinDefault(f *pflag.Flag) string {
+	if v, ok := f.Annotations[annotationDefault]; ok {
+		return v[0]
+	}
+	return f.DefValue
+}
+
+// Whether the flag has a value other than the built-in default from the config file
+func configSet(f *pflag.Flag) bool {
+	return !f.Changed && f.Value.String() != builtinDefault(f)
+}
+
+// Reset the value from the config file to the built-in default
+func resetConfig(f *pflag.Flag) error {
+	def := builtinDefault(f)
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		var values []string
+		if s := strings.Trim(def, "[]"); s != "" {
+			values = strings.Split(s, ",")
+		}
+		return sv.Replace(values)
+	}
+	return f.Value.Set(def)
+}
+
+// cobra checks mutually exclusive flags only if changed on the command line,
+// so e.g. 'output = "json"' in the config file with --rm is not detected.
+// A flag on the command line overrides the others in the group set by the config file,
+// e.g. --until with 'day = 30' in [prune]. It is an error only if both are in the config file.
+func checkExclusiveFlags(cmd *cobra.Command) error {
+	var groups []string
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		for _, g := range f.Annotations[annotationMutuallyExclusive] {
+			if !slices.Contains(groups, g) {
+				groups = append(groups, g)
+			}
+		}
+	})
+	slices.Sort(groups)
+
+	for _, g := range groups {
+		var (
+			changed bool
+			set     []*pflag.Flag
+		)
+		for _, name := range strings.Split(g, " ") {
+			f := cmd.Flags().Lookup(name)
+			if f == nil {
+				continue
+			}
+			if f.Changed {
+				changed = true
+			} else if configSet(f) {
+				set = append(set, f)
+			}
+		}
+
+		if changed {
+			// conflicts on the command line are reported by cobra
+			for _, f := range set {
+				if err := resetConfig(f); err != nil {
+					return fmt.Errorf("config: [%s] %s: %w", cmd.Name(), f.Name, err)
+				}
+				slog.Debug("config value overridden by mutually exclusive flag", "flag", f.Name, "group", g)
+			}
+			continue
+		}
+
+		if len(set) > 1 {
+			names := make([]string, len(set))
+			for i, f := range set {
+				names[i] = f.Name
+			}
+			slices.Sort(names)
+			return fmt.Errorf("config: [%s] options in the group [%s] cannot be set together; %v were all set", cmd.Name(), g, names)
+		}
+	}
+
+	return nil
+}
+
+type configCmd struct {
+	cmd *cobra.Command
+}
+
+func newConfigCmd() *configCmd {
+	root := &configCmd{}
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show the configuration",
+		Long: `Description:
+  Show the configuration file and the effective configuration.
+
+  The configuration file is read from $XDG_CONFIG_HOME/gtrash/config.toml ($HOME/.config/gtrash/config.toml).
+  Environment variables take precedence over the file, and command-line options take precedence over both.
+  See doc/configuration.md for details.`,
+		Example: `  # Show the effective configuration merged with environment variables
+  $ gtrash config show
+
+  # Show the path of the configuration file
+  $ gtrash config path`,
+		SilenceUsage:      true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:               "show",
+			Short:             "Show the effective configuration",
+			SilenceUsage:      true,
+			Args:              cobra.NoArgs,
+			ValidArgsFunction: cobra.NoFileCompletions,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return writeConfig(os.Stdout, cmd.Root())
+			},
+		},
+		&cobra.Command{
+			Use:               "path",
+			Short:             "Show the path of the configuration file",
+			SilenceUsage:      true,
+			Args:              cobra.NoArgs,
+			ValidArgsFunction: cobra.NoFileCompletions,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				fmt.Println(config.Path())
+				return nil
+			},
+		},
+	)
+
+	root.cmd = cmd
+	return root
+}
+
+// Write the effective configuration in TOML
+func writeConfig(w io.Writer, root *cobra.Command) error {
+	fmt.Fprintf(w, "# %s\n", posix.AbsPathToTilde(config.Path()))
+
+	m := env.Config.Mount
+	sections := []struct {
+		name   string
+		values [][2]any // key, value
+	}{
+		{values: [][2]any{
+			{"home_trash_dir", xdg.DirHomeTrash},
+			{"only_home_trash", env.ONLY_HOME_TRASH},
+			{"home_trash_fallback_copy", env.HOME_TRASH_FALLBACK_COPY},
+		}},
+		{name: "mount", values: [][2]any{
+			{"timeout", m.StatTimeout().String()},
+			{"include_fstypes", m.IncludeFSTypes},
+			{"exclude_fstypes", m.ExcludeFSTypes},
+			{"include_paths", m.IncludePaths},
+			{"exclude_paths", m.ExcludePaths},
+		}},
+		{name: "protect", values: [][2]any{
+			{"paths", env.Config.Protect.Paths},
+		}},
+	}
+
+	for _, sub := range root.Commands() {
+		if sub.Hidden || sub.Name() == "config" || sub.Name() == "completion" {
+			continue
+		}
+
+		var values [][2]any
+		sub.Flags().VisitAll(func(f *pflag.Flag) {
+			if !configurable(f) {
+				return
+			}
+
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				values = append(values, [2]any{f.Name, sv.GetSlice()})
+				return
+			}
+
+			var v any = f.DefValue
+			switch f.Value.Type() {
+			case "bool":
+				v, _ = strconv.ParseBool(f.DefValue)
+			case "int", "int64":
+				v, _ = strconv.ParseInt(f.DefValue, 10, 64)
+			}
+			values = append(values, [2]any{f.Name, v})
+		})
+		if len(values) > 0 {
+			sections = append(sections, struct {
+				name   string
+				values [][2]any
+			}{sub.Name(), values})
+		}
+	}
+
+	for _, sec := range sections {
+		if sec.name != "" {
+			fmt.Fprintf(w, "\n[%s]\n", sec.name)
+		}
+		for _, kv := range sec.values {
+			if err := writeTOMLValue(w, kv[0].(string), kv[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Write 'key = value' encoded by the TOML encoder, which escapes strings properly
+func writeTOMLValue(w io.Writer, key string, v any) error {
+	if list, ok := v.([]string); ok && list == nil {
+		// nil is omitted by the encoder
+		v = []string{}
+	}
+	return toml.NewEncoder(w).Encode(map[string]any{key: v})
+}
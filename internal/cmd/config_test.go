@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/config"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+func newTestConfigCmd() (*cobra.Command, *findOptions) {
+	var opts findOptions
+	find := &cobra.Command{Use: "find"}
+	find.Flags().VarP(&opts.sortBy, "sort", "s", "")
+	find.Flags().IntVarP(&opts.last, "last", "n", 0, "")
+	find.Flags().BoolVar(&opts.doRemove, "rm", false, "")
+	find.Flags().BoolVar(&opts.force, "force", false, "")
+	find.Flags().StringSliceVar(&opts.exts, "ext", nil, "")
+	noConfig(find.Flags(), "rm")
+	find.MarkFlagsMutuallyExclusive("sort", "rm")
+	envFlag(find.Flags(), "force", "GTRASH_TEST_FORCE")
+
+	root := &cobra.Command{Use: "gtrash"}
+	root.AddCommand(find)
+
+	return root, &opts
+}
+
+func TestApplyConfig(t *testing.T) {
+	t.Run("set defaults", func(t *testing.T) {
+		root, opts := newTestConfigCmd()
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"find": {"sort": "size", "last": int64(5), "force": true},
+		}})
+		require.NoError(t, err)
+
+		assert.Equal(t, trash.SortBySize, opts.sortBy)
+		assert.Equal(t, 5, opts.last)
+		assert.True(t, opts.force)
+
+		f := root.Commands()[0].Flags().Lookup("sort")
+		assert.Equal(t, "size", f.DefValue)
+		assert.False(t, f.Changed)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		root, opts := newTestConfigCmd()
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"find": {"ext": []any{"log", "txt"}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"log", "txt"}, opts.exts)
+
+		// replaced by command-line
+		require.NoError(t, root.Commands()[0].ParseFlags([]string{"--ext", "go"}))
+		assert.Equal(t, []string{"go"}, opts.exts)
+	})
+
+	t.Run("command-line takes precedence", func(t *testing.T) {
+		root, opts := newTestConfigCmd()
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"find": {"sort": "size"},
+		}})
+		require.NoError(t, err)
+
+		require.NoError(t, root.Commands()[0].ParseFlags([]string{"--sort", "name"}))
+		assert.Equal(t, trash.SortByName, opts.sortBy)
+	})
+
+	t.Run("environment variable takes precedence", func(t *testing.T) {
+		t.Setenv("GTRASH_TEST_FORCE", "false")
+
+		root, opts := newTestConfigCmd()
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"find": {"force": true},
+		}})
+		require.NoError(t, err)
+		assert.False(t, opts.force)
+	})
+
+	t.Run("mutually exclusive in the config file", func(t *testing.T) {
+		root, _ := newTestConfigCmd()
+		find := root.Commands()[0]
+		find.Flags().Bool("print0", false, "")
+		find.MarkFlagsMutuallyExclusive("sort", "print0")
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"find": {"sort": "size", "print0": true},
+		}})
+		require.NoError(t, err)
+
+		require.NoError(t, find.ParseFlags(nil))
+		assert.ErrorContains(t, checkExclusiveFlags(find), "[print0 sort] were all set")
+	})
+
+	t.Run("command-line overrides mutually exclusive config", func(t *testing.T) {
+		root, opts := newTestConfigCmd()
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"find": {"sort": "size"},
+		}})
+		require.NoError(t, err)
+
+		find := root.Commands()[0]
+		require.NoError(t, find.ParseFlags([]string{"--rm"}))
+		require.NoError(t, checkExclusiveFlags(find))
+		assert.True(t, opts.doRemove)
+		// back to the built-in default
+		assert.Equal(t, trash.SortByDeletedAt, opts.sortBy)
+	})
+
+	t.Run("time flag is reset", func(t *testing.T) {
+		var day int
+		var until trash.UntilFlag
+		prune := &cobra.Command{Use: "prune"}
+		prune.Flags().IntVar(&day, "day", 0, "")
+		prune.Flags().Var(&until, "until", "")
+		prune.MarkFlagsMutuallyExclusive("day", "until")
+		root := &cobra.Command{Use: "gtrash"}
+		root.AddCommand(prune)
+
+		err := applyConfig(root, &config.Config{Commands: map[string]map[string]any{
+			"prune": {"until": "2024-01-01"},
+		}})
+		require.NoError(t, err)
+
+		require.NoError(t, prune.ParseFlags([]string{"--day", "30"}))
+		require.NoError(t, checkExclusiveFlags(prune))
+		assert.Equal(t, 30, day)
+		assert.True(t, until.Time.IsZero())
+	})
+
+	errTests := []struct {
+		name   string
+		values map[string]map[string]any
+	}{
+		{name: "unknown command", values: map[string]map[string]any{"foo": {"sort": "size"}}},
+		{name: "unknown option", values: map[string]map[string]any{"find": {"foo": "size"}}},
+		{name: "not configurable", values: map[string]map[string]any{"find": {"rm": true}}},
+		{name: "invalid value", values: map[string]map[string]any{"find": {"sort": "foo"}}},
+		{name: "invalid type", values: map[string]map[string]any{"find": {"last": []any{int64(1)}}}},
+		{name: "invalid array", values: map[string]map[string]any{"find": {"ext": []any{int64(1)}}}},
+	}
+
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, _ := newTestConfigCmd()
+			assert.Error(t, applyConfig(root, &config.Config{Commands: tt.values}))
+		})
+	}
+}
+
+func TestWriteTOMLValue(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeTOMLValue(&buf, "home_trash_dir", "/tmp/a\x01\"b"))
+	require.NoError(t, writeTOMLValue(&buf, "paths", []string(nil)))
+	require.NoError(t, writeTOMLValue(&buf, "day", int64(30)))
+
+	var got map[string]any
+	_, err := toml.Decode(buf.String(), &got)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"home_trash_dir": "/tmp/a\x01\"b",
+		"paths":          []any{},
+		"day":            int64(30),
+	}, got)
+}
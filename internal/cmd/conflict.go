@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	cp "github.com/otiai10/copy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/umlx5h/gtrash/internal/env"
+	"github.com/umlx5h/gtrash/internal/trash"
+	"github.com/umlx5h/gtrash/internal/xdg"
+	"golang.org/x/exp/maps"
+)
+
+// --conflict
+
+var _ pflag.Value = (*conflictType)(nil)
+
+type conflictType int
+
+const (
+	conflictAsk       conflictType = iota // default: prompt in a terminal, fail otherwise
+	conflictRename                        // restore as "file (1).txt"
+	conflictOverwrite                     // move the existing file into the trash can first
+	conflictMerge                         // merge directories recursively
+	conflictNewer                         // keep whichever has the later mtime
+	conflictSkip                          // leave the file in the trash can
+)
+
+var (
+	conflictWellKnownStrings = map[string]conflictType{
+		"ask":       conflictAsk,
+		"rename":    conflictRename,
+		"overwrite": conflictOverwrite,
+		"merge":     conflictMerge,
+		"newer":     conflictNewer,
+		"skip":      conflictSkip,
+	}
+
+	conflictFlagCompletionFunc = trash.FlagCompletionFunc(
+		maps.Keys(conflictWellKnownStrings),
+	)
+
+	mergeRuleFlagCompletionFunc = trash.FlagCompletionFunc(
+		[]string{"rename", "overwrite", "newer", "skip"},
+	)
+)
+
+func (c *conflictType) Set(str string) error {
+	if value, ok := conflictWellKnownStrings[strings.ToLower(str)]; ok {
+		*c = value
+		return nil
+	}
+
+	return fmt.Errorf("must be %s", c.Type())
+}
+
+func (c conflictType) String() string {
+	switch c {
+	case conflictAsk:
+		return "ask"
+	case conflictRename:
+		return "rename"
+	case conflictOverwrite:
+		return "overwrite"
+	case conflictMerge:
+		return "merge"
+	case conflictNewer:
+		return "newer"
+	case conflictSkip:
+		return "skip"
+	default:
+		panic("invalid conflictType value")
+	}
+}
+
+func (c conflictType) Type() string {
+	return "ask|rename|overwrite|merge|newer|skip"
+}
+
+const conflictFlagUsage = `What to do when the restore path already exists
+ask (default):
+    Prompt in a terminal, fail otherwise
+
+rename:
+    Restore with a number, e.g. "file (1).txt"
+
+overwrite:
+    Move the existing file into the trash can, then restore
+
+merge:
+    Merge directories recursively, conflicting files are resolved by --merge-rule
+    Same as --merge-rule if either is not a directory
+
+newer:
+    Keep whichever has the later modification time
+    The existing file is moved into the trash can if the trashed one is newer
+
+skip:
+    Leave the file in the trash can`
+
+// --merge-rule, same as --conflict except ask and merge
+
+var _ pflag.Value = (*mergeRuleType)(nil)
+
+type mergeRuleType conflictType
+
+func (r *mergeRuleType) Set(str string) error {
+	var c conflictType
+	if err := c.Set(str); err != nil || c == conflictAsk || c == conflictMerge {
+		return fmt.Errorf("must be %s", r.Type())
+	}
+	*r = mergeRuleType(c)
+	return nil
+}
+
+func (r mergeRuleType) String() string {
+	return conflictType(r).String()
+}
+
+func (r mergeRuleType) Type() string {
+	return "rename|overwrite|newer|skip"
+}
+
+const mergeRuleFlagUsage = `What to do with conflicting files when merging directories
+rename|overwrite|newer|skip, same meaning as --conflict
+Files left in the trashed directory remain in the trash can`
+
+type conflictOptions struct {
+	policy    conflictType
+	mergeRule mergeRuleType
+}
+
+func addConflictFlags(cmd *cobra.Command, opts *conflictOptions) {
+	opts.mergeRule = mergeRuleType(conflictSkip)
+
+	cmd.Flags().Var(&opts.policy, "conflict", conflictFlagUsage)
+	cmd.Flags().Var(&opts.mergeRule, "merge-rule", mergeRuleFlagUsage)
+
+	if err := cmd.RegisterFlagCompletionFunc("conflict", conflictFlagCompletionFunc); err != nil {
+		panic(err)
+	}
+	if err := cmd.RegisterFlagCompletionFunc("merge-rule", mergeRuleFlagCompletionFunc); err != nil {
+		panic(err)
+	}
+}
+
+// Returns the first path not existing such as "dir/file (1).txt"
+// The extension is kept except for directories and dotfiles.
+func numberedPath(path string, isDir bool) string {
+	dir, name := filepath.Split(path)
+
+	base, ext := name, ""
+	if !isDir {
+		ext = filepath.Ext(name)
+		base = strings.TrimSuffix(name, ext)
+		if base == "" {
+			// dotfile such as .bashrc
+			base, ext = name, ""
+		}
+	}
+
+	for i := 1; ; i++ {
+		p := filepath.Join(dir, base+" ("+strconv.Itoa(i)+")"+ext)
+		if _, err := os.Lstat(p); errors.Is(err, fs.ErrNotExist) {
+			return p
+		}
+	}
+}
+
+// Whether src was modified after dst
+func isNewer(src, dst string) (bool, error) {
+	s, err := os.Lstat(src)
+	if err != nil {
+		return false, err
+	}
+	d, err := os.Lstat(dst)
+	if err != nil {
+		return false, err
+	}
+	return s.ModTime().After(d.ModTime()), nil
+}
+
+// Whether both are directories, symlinks are not followed
+func bothDir(src, dst string) bool {
+	s, err := os.Lstat(src)
+	if err != nil {
+		return false
+	}
+	d, err := os.Lstat(dst)
+	if err != nil {
+		return false
+	}
+	return s.IsDir() && d.IsDir()
+}
+
+// Move the existing file at path into the trash can to make room for the restored one
+func trashExisting(path string) error {
+	homeDir, externalDir, err := xdg.LookupTrashDir(path)
+	if err != nil && homeDir == nil && externalDir == nil {
+		return fmt.Errorf("lookup trash directory: %w", err)
+	}
+
+	var deleteTime time.Time
+
+	if externalDir != nil {
+		slog.Debug("trashing the existing file to the external trash", "path", path, "trashDir", externalDir.Dir)
+		if _, err := trashFile(*externalDir, path, &deleteTime, "", false); err == nil {
+			return nil
+		} else if homeDir == nil {
+			return err
+		}
+	}
+
+	slog.Debug("trashing the existing file to the home trash", "path", path, "trashDir", homeDir.Dir)
+	_, err = trashFile(*homeDir, path, &deleteTime, "", env.HOME_TRASH_FALLBACK_COPY)
+	return err
+}
+
+// rename(2), fallback to copy and delete
+func moveFile(from, to string) error {
+	slog.Debug("executing rename(2) to restore", "from", from, "to", to)
+	if err := os.Rename(from, to); err != nil {
+		slog.Debug("executing copy and delete to restore because rename(2) failed", "from", from, "to", to)
+
+		// copy recursively
+		if err := cp.Copy(from, to); err != nil {
+			return fmt.Errorf("fallback copy: %w", err)
+		}
+
+		// if copy success, then remove recursively
+		// The trashed file must be kept tracked by .trashinfo if it cannot be removed.
+		if err = os.RemoveAll(from); err != nil {
+			return fmt.Errorf("copied to %q but cannot delete the trashed file: %w", to, err)
+		}
+	}
+
+	return nil
+}
+
+// Move the contents of src into dst recursively, conflicting files are resolved by rule.
+// Returns the number of files left in src, src is removed if nothing is left.
+func mergeDir(src, dst string, rule conflictType) (left int, err error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range entries {
+		from := filepath.Join(src, e.Name())
+		to := filepath.Join(dst, e.Name())
+
+		if _, err := os.Lstat(to); errors.Is(err, fs.ErrNotExist) {
+			if err := moveFile(from, to); err != nil {
+				return left, err
+			}
+			continue
+		} else if err != nil {
+			return left, err
+		}
+
+		if bothDir(from, to) {
+			n, err := mergeDir(from, to, rule)
+			left += n
+			if err != nil {
+				return left, err
+			}
+			continue
+		}
+
+		switch rule {
+		case conflictRename:
+			to = numberedPath(to, e.IsDir())
+		case conflictOverwrite:
+			if err := trashExisting(to); err != nil {
+				return left, fmt.Errorf("trash %q: %w", to, err)
+			}
+		case conflictNewer:
+			newer, err := isNewer(from, to)
+			if err != nil {
+				return left, err
+			}
+			if !newer {
+				left++
+				continue
+			}
+			if err := trashExisting(to); err != nil {
+				return left, fmt.Errorf("trash %q: %w", to, err)
+			}
+		default: // skip
+			left++
+			continue
+		}
+
+		if err := moveFile(from, to); err != nil {
+			return left, err
+		}
+	}
+
+	if left == 0 {
+		if err := os.Remove(src); err != nil {
+			return 0, err
+		}
+	}
+
+	return left, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string, mtime time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestNumberedPath(t *testing.T) {
+	tmp := t.TempDir()
+	now := time.Now()
+
+	assert.Equal(t, filepath.Join(tmp, "file (1).txt"), numberedPath(filepath.Join(tmp, "file.txt"), false))
+	assert.Equal(t, filepath.Join(tmp, ".bashrc (1)"), numberedPath(filepath.Join(tmp, ".bashrc"), false))
+	assert.Equal(t, filepath.Join(tmp, "dir.d (1)"), numberedPath(filepath.Join(tmp, "dir.d"), true))
+
+	writeFile(t, filepath.Join(tmp, "file (1).txt"), "", now)
+	writeFile(t, filepath.Join(tmp, "file (2).txt"), "", now)
+	assert.Equal(t, filepath.Join(tmp, "file (3).txt"), numberedPath(filepath.Join(tmp, "file.txt"), false))
+}
+
+func TestMergeDir(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	setup := func(t *testing.T) (src, dst string) {
+		tmp := t.TempDir()
+		src = filepath.Join(tmp, "trash", "project")
+		dst = filepath.Join(tmp, "project")
+
+		writeFile(t, filepath.Join(src, "only-trash.txt"), "trash", now)
+		writeFile(t, filepath.Join(src, "sub", "nested.txt"), "trash", now)
+		writeFile(t, filepath.Join(src, "old.txt"), "trash", old)
+		writeFile(t, filepath.Join(src, "new.txt"), "trash", now)
+
+		writeFile(t, filepath.Join(dst, "sub", "other.txt"), "dst", now)
+		writeFile(t, filepath.Join(dst, "old.txt"), "dst", now)
+		writeFile(t, filepath.Join(dst, "new.txt"), "dst", old)
+		return src, dst
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		src, dst := setup(t)
+
+		left, err := mergeDir(src, dst, conflictSkip)
+		require.NoError(t, err)
+		assert.Equal(t, 2, left)
+
+		assert.Equal(t, "trash", readFile(t, filepath.Join(dst, "only-trash.txt")))
+		assert.Equal(t, "trash", readFile(t, filepath.Join(dst, "sub", "nested.txt")))
+		assert.Equal(t, "dst", readFile(t, filepath.Join(dst, "sub", "other.txt")))
+		assert.Equal(t, "dst", readFile(t, filepath.Join(dst, "old.txt")))
+		assert.Equal(t, "dst", readFile(t, filepath.Join(dst, "new.txt")))
+
+		// conflicting files are left, merged directories are removed
+		assert.FileExists(t, filepath.Join(src, "old.txt"))
+		assert.FileExists(t, filepath.Join(src, "new.txt"))
+		assert.NoDirExists(t, filepath.Join(src, "sub"))
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		src, dst := setup(t)
+
+		left, err := mergeDir(src, dst, conflictRename)
+		require.NoError(t, err)
+		assert.Equal(t, 0, left)
+
+		assert.Equal(t, "dst", readFile(t, filepath.Join(dst, "old.txt")))
+		assert.Equal(t, "trash", readFile(t, filepath.Join(dst, "old (1).txt")))
+		assert.Equal(t, "trash", readFile(t, filepath.Join(dst, "new (1).txt")))
+		assert.NoDirExists(t, src)
+	})
+
+	t.Run("newer keeps the existing newer file", func(t *testing.T) {
+		src, dst := setup(t)
+		// only old.txt conflicts
+		require.NoError(t, os.Remove(filepath.Join(src, "new.txt")))
+
+		left, err := mergeDir(src, dst, conflictNewer)
+		require.NoError(t, err)
+		assert.Equal(t, 1, left)
+		assert.Equal(t, "dst", readFile(t, filepath.Join(dst, "old.txt")))
+		assert.FileExists(t, filepath.Join(src, "old.txt"))
+	})
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/umlx5h/gtrash/internal/posix"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	maxDiffSize  = 1 << 20  // 1MB, larger files are compared by hash
+	maxHashSize  = 64 << 20 // 64MB, larger files are compared only by size and mtime
+	maxDiffLines = 100      // for text diffs and directory listings
+)
+
+// Show how the trashed file differs from the existing file at the restore path,
+// used to decide how to resolve the conflict.
+func printConflictDiff(w io.Writer, trashPath, restorePath string) error {
+	trashed, err := os.Lstat(trashPath)
+	if err != nil {
+		return err
+	}
+	existing, err := os.Lstat(restorePath)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case trashed.IsDir() && existing.IsDir():
+		return printDirDiff(w, trashPath, restorePath)
+	case trashed.Mode().IsRegular() && existing.Mode().IsRegular():
+		trashedText, err := isTextFile(trashPath, trashed.Size())
+		if err != nil {
+			return err
+		}
+		existingText, err := isTextFile(restorePath, existing.Size())
+		if err != nil {
+			return err
+		}
+		if trashedText && existingText {
+			return printTextDiff(w, trashPath, restorePath, trashed, existing)
+		}
+	}
+
+	return printFileCompare(w, trashPath, restorePath, trashed, existing)
+}
+
+func isTextFile(path string, size int64) (bool, error) {
+	if size > maxDiffSize {
+		return false, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	binary, err := posix.IsBinary(f, size)
+	if err != nil {
+		return false, err
+	}
+	return !binary, nil
+}
+
+// Unified diff from the existing file to the trashed file
+func printTextDiff(w io.Writer, trashPath, restorePath string, trashed, existing fs.FileInfo) error {
+	a, err := os.ReadFile(restorePath)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(trashPath)
+	if err != nil {
+		return err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(a)),
+		B:        splitLines(string(b)),
+		FromFile: restorePath + " (existing)",
+		FromDate: existing.ModTime().Format(time.DateTime),
+		ToFile:   trashPath + " (trashed)",
+		ToDate:   trashed.ModTime().Format(time.DateTime),
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+
+	if diff == "" {
+		fmt.Fprintln(w, "Files are identical")
+		return nil
+	}
+
+	var (
+		green = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+		red   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		cyan  = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	)
+
+	lines := strings.SplitAfter(diff, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		if i == maxDiffLines {
+			fmt.Fprintf(w, "... and %d more lines\n", len(lines)-maxDiffLines)
+			break
+		}
+		if !isTerminal {
+			fmt.Fprint(w, line)
+			continue
+		}
+
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			text = lipgloss.NewStyle().Bold(true).Render(text)
+		case strings.HasPrefix(line, "@@"):
+			text = cyan.Render(text)
+		case strings.HasPrefix(line, "+"):
+			text = green.Render(text)
+		case strings.HasPrefix(line, "-"):
+			text = red.Render(text)
+		}
+		fmt.Fprintln(w, text)
+	}
+
+	return nil
+}
+
+// Unlike difflib.SplitLines, no empty line is added after the last newline
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		// no newline at end of file
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
+
+// Compare type, size, mtime and hash for binaries and special files
+func printFileCompare(w io.Writer, trashPath, restorePath string, trashed, existing fs.FileInfo) error {
+	existingHash, err := fileHash(restorePath, existing)
+	if err != nil {
+		return err
+	}
+	trashedHash, err := fileHash(trashPath, trashed)
+	if err != nil {
+		return err
+	}
+
+	row := func(name, a, b string) {
+		fmt.Fprintf(w, "%-10s %-30s %s\n", name, a, b)
+	}
+
+	row("", "existing", "trashed")
+	row("Type", posix.FileType(existing), posix.FileType(trashed))
+	row("Size", humanize.Bytes(uint64(existing.Size())), humanize.Bytes(uint64(trashed.Size())))
+	row("Modified", existing.ModTime().Format(time.DateTime), trashed.ModTime().Format(time.DateTime))
+	row("SHA-256", existingHash, trashedHash)
+
+	switch {
+	case existingHash != "-" && trashedHash != "-":
+		if existingHash == trashedHash {
+			fmt.Fprintln(w, "\nContents are identical")
+		} else {
+			fmt.Fprintln(w, "\nContents differ")
+		}
+	case existing.Mode().IsRegular() && trashed.Mode().IsRegular() && existing.Size() != trashed.Size():
+		// too large to hash
+		fmt.Fprintln(w, "\nContents differ")
+	}
+
+	return nil
+}
+
+// First 16 characters of SHA-256, "-" if not a regular file or too large to read
+func fileHash(path string, fi fs.FileInfo) (string, error) {
+	if !fi.Mode().IsRegular() || fi.Size() > maxHashSize {
+		return "-", nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+type diffEntry struct {
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+}
+
+// Files in the directory by relative path, symlinks are not followed
+func listDiffEntries(dir string) (map[string]diffEntry, error) {
+	entries := make(map[string]diffEntry)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entries[rel] = diffEntry{mode: fi.Mode().Type(), size: fi.Size(), modTime: fi.ModTime()}
+		return nil
+	})
+
+	return entries, err
+}
+
+// Listing diff of the directories
+// +: only in trashed, -: only in existing, ~: both but differ
+func printDirDiff(w io.Writer, trashPath, restorePath string) error {
+	existing, err := listDiffEntries(restorePath)
+	if err != nil {
+		return err
+	}
+	trashed, err := listDiffEntries(trashPath)
+	if err != nil {
+		return err
+	}
+
+	paths := maps.Keys(existing)
+	for p := range trashed {
+		if _, ok := existing[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+
+	var (
+		lines                    []string
+		added, removed, modified int
+	)
+	for _, p := range paths {
+		e, inExisting := existing[p]
+		t, inTrashed := trashed[p]
+
+		name := p
+		if (inTrashed && t.mode.IsDir()) || (!inTrashed && e.mode.IsDir()) {
+			name += string(filepath.Separator)
+		}
+
+		switch {
+		case !inExisting:
+			added++
+			lines = append(lines, "+ "+name)
+		case !inTrashed:
+			removed++
+			lines = append(lines, "- "+name)
+		case e.mode != t.mode || (!t.mode.IsDir() && (e.size != t.size || !e.modTime.Equal(t.modTime))):
+			modified++
+			lines = append(lines, "~ "+name)
+		}
+	}
+
+	fmt.Fprintf(w, "--- %s (existing)\n+++ %s (trashed)\n", restorePath, trashPath)
+	for i, line := range lines {
+		if i == maxDiffLines {
+			fmt.Fprintf(w, "... and %d more\n", len(lines)-maxDiffLines)
+			break
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "%d only in trashed, %d only in existing, %d differ\n", added, removed, modified)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintConflictDiff(t *testing.T) {
+	now := time.Now()
+
+	t.Run("text", func(t *testing.T) {
+		tmp := t.TempDir()
+		trashed := filepath.Join(tmp, "trashed.txt")
+		existing := filepath.Join(tmp, "existing.txt")
+		writeFile(t, trashed, "a\nb\nc\n", now)
+		writeFile(t, existing, "a\nB\nc\n", now)
+
+		var out strings.Builder
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		assert.Contains(t, out.String(), "--- "+existing+" (existing)")
+		assert.Contains(t, out.String(), "+++ "+trashed+" (trashed)")
+		assert.Contains(t, out.String(), "-B\n+b\n")
+
+		out.Reset()
+		writeFile(t, existing, "a\nb\nc\n", now)
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		assert.Equal(t, "Files are identical\n", out.String())
+	})
+
+	t.Run("long text is truncated", func(t *testing.T) {
+		tmp := t.TempDir()
+		trashed := filepath.Join(tmp, "trashed.txt")
+		existing := filepath.Join(tmp, "existing.txt")
+		writeFile(t, trashed, strings.Repeat("a\n", 200), now)
+		writeFile(t, existing, strings.Repeat("b\n", 200), now)
+
+		var out strings.Builder
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		// 3 header lines + 400 changed lines
+		assert.Len(t, lines, maxDiffLines+1)
+		assert.Equal(t, "... and 303 more lines", lines[maxDiffLines])
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		tmp := t.TempDir()
+		trashed := filepath.Join(tmp, "trashed.bin")
+		existing := filepath.Join(tmp, "existing.bin")
+		writeFile(t, trashed, "\x00\x01\x02", now)
+		writeFile(t, existing, "\x00\x01\x03", now)
+
+		var out strings.Builder
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		assert.Contains(t, out.String(), "SHA-256")
+		assert.Contains(t, out.String(), "Contents differ")
+	})
+
+	t.Run("large files are not hashed", func(t *testing.T) {
+		tmp := t.TempDir()
+		trashed := filepath.Join(tmp, "trashed.bin")
+		existing := filepath.Join(tmp, "existing.bin")
+		writeFile(t, trashed, "", now)
+		writeFile(t, existing, "", now)
+		require.NoError(t, os.Truncate(trashed, maxHashSize+1))
+		require.NoError(t, os.Truncate(existing, maxHashSize+2))
+
+		var out strings.Builder
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		assert.Regexp(t, `SHA-256 +- +-`, out.String())
+		assert.Contains(t, out.String(), "Contents differ")
+
+		// same size, cannot tell
+		require.NoError(t, os.Truncate(existing, maxHashSize+1))
+		out.Reset()
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		assert.NotContains(t, out.String(), "Contents")
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		tmp := t.TempDir()
+		trashed := filepath.Join(tmp, "trashed")
+		existing := filepath.Join(tmp, "existing")
+		writeFile(t, filepath.Join(trashed, "same.txt"), "x", now)
+		writeFile(t, filepath.Join(existing, "same.txt"), "x", now)
+		writeFile(t, filepath.Join(trashed, "changed.txt"), "xx", now)
+		writeFile(t, filepath.Join(existing, "changed.txt"), "x", now)
+		writeFile(t, filepath.Join(trashed, "sub", "added.txt"), "x", now)
+		writeFile(t, filepath.Join(existing, "removed.txt"), "x", now)
+
+		var out strings.Builder
+		require.NoError(t, printConflictDiff(&out, trashed, existing))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, []string{
+			"--- " + existing + " (existing)",
+			"+++ " + trashed + " (trashed)",
+			"~ changed.txt",
+			"- removed.txt",
+			"+ sub/",
+			"+ sub/added.txt",
+			"2 only in trashed, 1 only in existing, 1 differ",
+		}, lines)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Error(t, printConflictDiff(&strings.Builder{}, filepath.Join(t.TempDir(), "missing"), os.TempDir()))
+	})
+}
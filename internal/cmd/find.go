@@ -30,13 +30,21 @@ type findOptions struct {
 	doRemove  bool
 	doRestore bool
 	force     bool
+	dryRun    bool
 
 	dayNew int // unit day
 	dayOld int
+	since  trash.TimeFlag
+	until  trash.UntilFlag
 
 	sizeLarge string
 	sizeSmall string
 
+	types []string
+	exts  []string
+	owner string
+	where string
+
 	reverse bool
 	last    int
 
@@ -45,8 +53,12 @@ type findOptions struct {
 	showTrashPath bool
 
 	restoreTo string
+	conflict  conflictOptions
 
 	trashDir string
+
+	output outputType
+	print0 bool
 }
 
 func newFindCmd() *findCmd {
@@ -88,12 +100,33 @@ func newFindCmd() *findCmd {
   # Remove files deleted over a week ago
   $ gtrash find --day-old 7 --rm
 
+  # Show files which would be removed without removing them
+  $ gtrash find --day-old 7 --rm --dry-run
+
+  # Show files deleted in the last 2 hours
+  $ gtrash find --since 2h
+
+  # Show files deleted on 2024-01-02
+  $ gtrash find --since 2024-01-02 --until 2024-01-02
+
   # Remove trashed files larger than 10MB
   $ gtrash find --size-large 10mb --rm
 
+  # Show only directories
+  $ gtrash find --type d
+
+  # Remove log files
+  $ gtrash find --ext log --rm
+
+  # Print trashed files as JSON including size
+  $ gtrash find -S --output json
+
   # Fuzzy find multiple items and remove them permanently
   # The -o in xargs is necessary for the confirmation prompt to display.
-  $ gtrash find | fzf --multi | awk -F'\t' '{print $2}' | xargs -o gtrash rm`,
+  $ gtrash find | fzf --multi | awk -F'\t' '{print $2}' | xargs -o gtrash rm
+
+  # Same as above, but safe for paths containing tabs or newlines
+  $ gtrash find --print0 | fzf --multi --read0 --print0 | gtrash rm -0`,
 		SilenceUsage: true,
 		RunE: func(_ *cobra.Command, args []string) error {
 			if err := findCmdRun(args, root.opts); err != nil {
@@ -133,8 +166,14 @@ full:
 	cmd.Flags().BoolVar(&root.opts.doRestore, "restore", false, "Do restore")
 	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always do --rm or --restore without confirmation prompt
 This is not necessary if running outside of a terminal`)
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage+`
+Used with --rm or --restore`)
 	cmd.Flags().IntVar(&root.opts.dayNew, "day-new", 0, "Filter by deletion date (within X day)")
 	cmd.Flags().IntVar(&root.opts.dayOld, "day-old", 0, "Filter by deletion date (before X day)")
+	cmd.Flags().Var(&root.opts.since, "since", sinceFlagUsage)
+	cmd.Flags().Var(&root.opts.until, "until", untilFlagUsage)
+	addAttrFilterFlags(cmd, &root.opts.types, &root.opts.exts, &root.opts.owner)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
 	cmd.Flags().BoolVarP(&root.opts.showSize, "show-size", "S", false, `Show size always
 Automatically enabled if --sort size, --size-large, --size-small specified
 
@@ -146,6 +185,7 @@ The folder size is cached, so it will run faster the next time.
 	cmd.Flags().BoolVar(&root.opts.showTrashPath, "show-trashpath", false, "Show trash path")
 	cmd.Flags().BoolVarP(&root.opts.reverse, "reverse", "r", false, "Reverse sort order (default: ascending)")
 	cmd.Flags().StringVar(&root.opts.restoreTo, "restore-to", "", "Restore to this path instead of original path")
+	addConflictFlags(cmd, &root.opts.conflict)
 	cmd.Flags().IntVarP(&root.opts.last, "last", "n", 0, "Show n last files")
 	cmd.Flags().StringVar(&root.opts.trashDir, "trash-dir", "", `Specify a full path if you want to search only a specific trash can
 By default, all trash cans are searched.
@@ -154,9 +194,23 @@ For $HOME trash only:
     --trash-dir "$HOME/.local/share/Trash"
 `)
 
+	cmd.Flags().VarP(&root.opts.output, "output", "o", outputFlagUsage)
+	cmd.Flags().BoolVar(&root.opts.print0, "print0", false, `Print only original paths delimited by NUL instead of the table
+Can be passed to 'put', 'restore' and 'rm' with -0`)
+
 	cmd.MarkFlagsMutuallyExclusive("rm", "restore")
+	cmd.MarkFlagsMutuallyExclusive("output", "rm")
+	cmd.MarkFlagsMutuallyExclusive("output", "restore")
+	cmd.MarkFlagsMutuallyExclusive("print0", "output")
+	cmd.MarkFlagsMutuallyExclusive("print0", "rm")
+	cmd.MarkFlagsMutuallyExclusive("print0", "restore")
+
+	// actions must be specified explicitly
+	noConfig(cmd.Flags(), "rm", "restore", "print0", "force")
+
 	cmd.MarkFlagsMutuallyExclusive("directory", "cwd")
-	cmd.MarkFlagsMutuallyExclusive("day-new", "day-old")
+	cmd.MarkFlagsMutuallyExclusive("day-new", "since")
+	cmd.MarkFlagsMutuallyExclusive("day-old", "until")
 	cmd.MarkFlagsMutuallyExclusive("size-large", "size-small")
 
 	if err := cmd.RegisterFlagCompletionFunc("sort", trash.SortByFlagCompletionFunc); err != nil {
@@ -165,6 +219,9 @@ For $HOME trash only:
 	if err := cmd.RegisterFlagCompletionFunc("mode", trash.ModeByFlagCompletionFunc); err != nil {
 		panic(err)
 	}
+	if err := cmd.RegisterFlagCompletionFunc("output", outputFlagCompletionFunc); err != nil {
+		panic(err)
+	}
 
 	root.cmd = cmd
 	return root
@@ -185,8 +242,13 @@ func findCmdRun(args []string, opts findOptions) error {
 		trash.WithQueries(args),
 		trash.WithSortBy(opts.sortBy),
 		trash.WithQueryMode(opts.modeBy),
-		trash.WithDay(opts.dayNew, opts.dayOld), // TODO: also set in restore?
+		trash.WithDay(opts.dayNew, opts.dayOld),
+		trash.WithTimeRange(opts.since.Time, opts.until.Time),
 		trash.WithSize(opts.sizeLarge, opts.sizeSmall),
+		trash.WithTypes(opts.types),
+		trash.WithExts(opts.exts),
+		trash.WithOwner(opts.owner),
+		trash.WithWhere(opts.where),
 		trash.WithLimitLast(opts.last),
 		trash.WithTrashDir(opts.trashDir),
 	)
@@ -195,11 +257,25 @@ func findCmdRun(args []string, opts findOptions) error {
 		if opts.doRemove && errors.Is(err, trash.ErrNotFound) {
 			fmt.Printf("do nothing: %s\n", err)
 			return nil
+		} else if opts.output.isJSON() && errors.Is(err, trash.ErrNotFound) {
+			// empty result is not an error for machine-readable output
+			return writeJSON[fileJSON](os.Stdout, opts.output, nil)
 		} else {
 			return err
 		}
 	}
 
+	if opts.output.isJSON() {
+		return writeJSON(os.Stdout, opts.output, newFilesJSON(box.Files))
+	}
+
+	if opts.print0 {
+		for _, f := range box.Files {
+			fmt.Printf("%s\x00", f.OriginalPath)
+		}
+		return nil
+	}
+
 	listFiles(box.Files, box.GetSize, opts.showTrashPath)
 
 	if !opts.doRemove && !opts.doRestore {
@@ -208,6 +284,9 @@ func findCmdRun(args []string, opts findOptions) error {
 			if len(box.OrphanMeta) > 0 {
 				fmt.Printf("\nFound invalid metadata: %d\nYou can remove invalid metadata by 'gtrash metafix'\n", len(box.OrphanMeta))
 			}
+			if len(box.OrphanFiles) > 0 {
+				fmt.Printf("\nFound files without metadata: %d\nYou can remove or adopt them by 'gtrash metafix'\n", len(box.OrphanFiles))
+			}
 		}
 		return nil
 	}
@@ -215,20 +294,20 @@ func findCmdRun(args []string, opts findOptions) error {
 	fmt.Printf("\nFound %d trashed files\n", len(box.Files))
 
 	if opts.doRemove {
-		if !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
+		if !opts.dryRun && !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
 			return errors.New("do nothing")
 		}
-		doRemove(box.Files)
+		doRemove(box.Files, opts.dryRun)
 
 	} else if opts.doRestore {
 		if opts.restoreTo != "" {
 			fmt.Printf("Will restore to %q instead of original path\n", opts.restoreTo)
 		}
 
-		if !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
+		if !opts.dryRun && !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
 			return errors.New("do nothing")
 		}
-		if err := doRestore(box.Files, opts.restoreTo, isTerminal && !opts.force); err != nil {
+		if err := doRestore(box.Files, opts.restoreTo, opts.conflict, isTerminal && !opts.force, opts.dryRun); err != nil {
 			return err
 		}
 	}
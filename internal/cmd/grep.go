@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/parallel"
+	"github.com/umlx5h/gtrash/internal/trash"
+	"github.com/umlx5h/gtrash/internal/tui"
+)
+
+type grepCmd struct {
+	cmd  *cobra.Command
+	opts grepOptions
+}
+
+type grepOptions struct {
+	ignoreCase bool
+	fixed      bool
+	filesOnly  bool
+
+	directory string
+	cwd       bool
+	since     trash.TimeFlag
+	until     trash.UntilFlag
+	where     string
+	trashDir  string
+
+	// do options
+	doRestore bool
+	restoreTo string
+	conflict  conflictOptions
+	force     bool
+	dryRun    bool
+}
+
+func newGrepCmd() *grepCmd {
+	root := &grepCmd{}
+	cmd := &cobra.Command{
+		Use:   "grep PATTERN",
+		Short: "Search the content of trashed files",
+		Long: `Description:
+  Searches the content of trashed regular files and files inside trashed directories line by line.
+  Matched lines are displayed with the original path and line number.
+  Binary files and symbolic links are skipped.
+
+  PATTERN is a Go language regular expression.
+  To restore the trashed files containing matched lines, use the --restore option.`,
+		Example: `  # Search trashed files containing "TODO"
+  $ gtrash grep TODO
+
+  # Ignore case and treat the pattern as a literal string
+  $ gtrash grep -iF 'func main('
+
+  # Show only trashed files containing matched lines
+  $ gtrash grep -l 'api_key'
+
+  # Restore trashed files containing matched lines
+  $ gtrash grep 'api_key' --restore
+
+  # Same as above, but select files to restore with fzf
+  $ gtrash grep -l 'api_key' | fzf --multi | xargs -o gtrash restore`,
+		SilenceUsage:      true,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := grepCmdRun(args[0], root.opts); err != nil {
+				return err
+			}
+			if glog.ExitCode() > 0 {
+				return errContinue
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&root.opts.ignoreCase, "ignore-case", "i", false, "Ignore case distinctions in PATTERN")
+	cmd.Flags().BoolVarP(&root.opts.fixed, "fixed-strings", "F", false, "Interpret PATTERN as a literal string")
+	cmd.Flags().BoolVarP(&root.opts.filesOnly, "files-with-matches", "l", false, `Print only original paths of trashed files containing matched lines
+For a trashed directory, the path of the directory is printed`)
+	cmd.Flags().StringVarP(&root.opts.directory, "directory", "d", "", "Filter by directory")
+	cmd.Flags().BoolVarP(&root.opts.cwd, "cwd", "c", false, "Filter by current working directory")
+	cmd.Flags().Var(&root.opts.since, "since", sinceFlagUsage)
+	cmd.Flags().Var(&root.opts.until, "until", untilFlagUsage)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
+	cmd.Flags().StringVar(&root.opts.trashDir, "trash-dir", "", "Specify a full path if you want to search only a specific trash can")
+	cmd.Flags().BoolVar(&root.opts.doRestore, "restore", false, "Restore trashed files containing matched lines")
+	cmd.Flags().StringVar(&root.opts.restoreTo, "restore-to", "", "Restore to this path instead of original path")
+	addConflictFlags(cmd, &root.opts.conflict)
+	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always do --restore without confirmation prompt
+This is not necessary if running outside of a terminal`)
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage+`
+Used with --restore`)
+
+	cmd.MarkFlagsMutuallyExclusive("directory", "cwd")
+	cmd.MarkFlagsMutuallyExclusive("files-with-matches", "restore")
+
+	// actions must be specified explicitly
+	noConfig(cmd.Flags(), "restore", "force")
+
+	root.cmd = cmd
+	return root
+}
+
+func grepCmdRun(pattern string, opts grepOptions) error {
+	slog.Debug("starting grep", "pattern", pattern, "doRestore", opts.doRestore)
+
+	if err := checkOptRestoreTo(&opts.restoreTo); err != nil {
+		return err
+	}
+
+	if opts.fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	box := trash.NewBox(
+		trash.WithAscend(true),
+		trash.WithDirectory(opts.directory),
+		trash.WithCWD(opts.cwd),
+		trash.WithTimeRange(opts.since.Time, opts.until.Time),
+		trash.WithWhere(opts.where),
+		trash.WithTrashDir(opts.trashDir),
+	)
+	if err := box.Open(); err != nil {
+		return err
+	}
+
+	// search concurrently, reading files is I/O bound
+	type result struct {
+		matches []trash.GrepMatch
+		err     error
+	}
+	results := make([]result, len(box.Files))
+	parallel.Do(len(box.Files), parallel.Workers(), func(i int) {
+		matches, err := box.Files[i].Grep(re, opts.filesOnly)
+		results[i] = result{matches: matches, err: err}
+	})
+
+	var files []trash.File
+	for i, r := range results {
+		if r.err != nil {
+			glog.Errorf("cannot search %q: %s\n", box.Files[i].TrashPath, r.err)
+			continue
+		}
+		if len(r.matches) == 0 {
+			continue
+		}
+		files = append(files, box.Files[i])
+
+		if opts.filesOnly {
+			fmt.Println(box.Files[i].OriginalPath)
+			continue
+		}
+		for _, m := range r.matches {
+			printGrepMatch(m, re)
+		}
+	}
+
+	if len(files) == 0 {
+		return errors.New("no matches found")
+	}
+
+	if !opts.doRestore {
+		return nil
+	}
+
+	fmt.Println()
+	listFiles(files, false, false)
+	fmt.Printf("\nFound %d trashed files\n", len(files))
+
+	if opts.restoreTo != "" {
+		fmt.Printf("Will restore to %q instead of original path\n", opts.restoreTo)
+	}
+
+	if !opts.dryRun && !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
+		return errors.New("do nothing")
+	}
+
+	return doRestore(files, opts.restoreTo, opts.conflict, isTerminal && !opts.force, opts.dryRun)
+}
+
+// Print in the same format as grep -n
+func printGrepMatch(m trash.GrepMatch, re *regexp.Regexp) {
+	if !isTerminal {
+		fmt.Printf("%s:%d:%s\n", m.Path, m.Line, m.Text)
+		return
+	}
+
+	var (
+		magenta = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+		green   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+		red     = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	)
+
+	// highlight matched parts
+	var text strings.Builder
+	var last int
+	for _, loc := range re.FindAllStringIndex(m.Text, -1) {
+		text.WriteString(m.Text[last:loc[0]])
+		text.WriteString(red.Render(m.Text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	text.WriteString(m.Text[last:])
+
+	fmt.Printf("%s:%s:%s\n", magenta.Render(m.Path), green.Render(fmt.Sprint(m.Line)), text.String())
+}
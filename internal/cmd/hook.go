@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+
+	"github.com/umlx5h/gtrash/internal/hook"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+// Run the pre-hook for one file, the file must be skipped if an error is returned
+func runPreHook(name hook.Name, file trash.File) error {
+	return hook.Run(name, newFilesJSON([]trash.File{file}))
+}
+
+// Run the post-hook for the affected files, failure is only warned
+func runPostHook(name hook.Name, files []trash.File) {
+	if len(files) == 0 {
+		return
+	}
+	if err := hook.Run(name, newFilesJSON(files)); err != nil {
+		slog.Warn("post hook failed", "hook", name, "error", err)
+	}
+}
+
+// Hold output of hooks run in the TUI (e.g. pre-remove), which would corrupt the screen.
+// The returned func prints it and must be called after the TUI exits.
+func holdHookOutput() (release func()) {
+	var buf bytes.Buffer
+	hook.Output = &buf
+
+	return func() {
+		hook.Output = os.Stderr
+		os.Stderr.Write(buf.Bytes())
+	}
+}
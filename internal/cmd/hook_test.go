@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/hook"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+func TestHoldHookOutput(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	require.NoError(t, os.MkdirAll(hook.Dir(), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hook.Dir(), string(hook.PreRemove)),
+		[]byte("#!/bin/sh\necho hook stdout\necho hook stderr >&2\n"), 0o755))
+
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	require.NoError(t, err)
+	defer stderr.Close()
+
+	orig := os.Stderr
+	os.Stderr = stderr
+	defer func() {
+		os.Stderr = orig
+		hook.Output = orig
+	}()
+
+	release := holdHookOutput()
+	require.NoError(t, runPreHook(hook.PreRemove, trash.File{OriginalPath: "/foo"}))
+
+	b, err := os.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Empty(t, b, "nothing is printed while the TUI is running")
+
+	release()
+	assert.Equal(t, os.Stderr, hook.Output)
+
+	b, err = os.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "hook stdout\nhook stderr\n", string(b))
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const (
+	fromFileFlagUsage = `Read paths from FILE in addition to the command-line arguments
+One path per line, or NUL-delimited with -0
+Use '-' to read from stdin, confirmations are then read from the terminal`
+
+	nullFlagUsage = `Paths are delimited by NUL instead of newline
+Read from stdin if --from-file is not specified, confirmations are then read from the terminal`
+)
+
+// Returns args appended with paths read by --from-file and -0
+// If fromFile is empty and null is true, stdin is used.
+func argsWithPathList(args []string, fromFile string, null bool) ([]string, error) {
+	if fromFile == "" && !null {
+		return args, nil
+	}
+
+	var r io.Reader
+	if fromFile == "" || fromFile == "-" {
+		r = os.Stdin
+		promptFromTTY()
+	} else {
+		f, err := os.Open(fromFile)
+		if err != nil {
+			return nil, fmt.Errorf("--from-file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	paths, err := readPathList(r, null)
+	if err != nil {
+		return nil, fmt.Errorf("read path list: %w", err)
+	}
+
+	return append(args, paths...), nil
+}
+
+// stdin is consumed by the path list, so isTerminal is false and every confirmation would be skipped.
+// Prompts read from /dev/tty instead (bubbletea opens it if stdin is not a terminal),
+// so that e.g. 'find --print0 | fzf --read0 --print0 | gtrash rm -0' still asks before removing.
+// Without a terminal, prompts are skipped same as other input from pipes.
+// isTerminal is left as is, only confirmations of the commands reading path lists use canPrompt.
+func promptFromTTY() {
+	if canPrompt || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		slog.Debug("cannot open tty, confirmations are skipped", "error", err)
+		return
+	}
+	tty.Close()
+
+	canPrompt = true
+}
+
+// Read paths delimited by newline or NUL
+// Empty entries are skipped.
+func readPathList(r io.Reader, null bool) ([]string, error) {
+	s := bufio.NewScanner(r)
+	// allow paths longer than the default token size (64KB)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if null {
+		s.Split(scanNull)
+	}
+
+	var paths []string
+	for s.Scan() {
+		if p := s.Text(); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// bufio.SplitFunc for NUL-delimited input, same as bufio.ScanLines except the delimiter
+func scanNull(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[0:i], nil
+	}
+	// last entry without a terminating NUL
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
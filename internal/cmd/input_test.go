@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPathList(t *testing.T) {
+	t.Run("newline", func(t *testing.T) {
+		got, err := readPathList(strings.NewReader("/foo/a\n\n/foo/b c\n/foo/d"), false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/foo/a", "/foo/b c", "/foo/d"}, got)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		got, err := readPathList(strings.NewReader("/foo/a\tb\x00/foo/new\nline\x00\x00/foo/last"), true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/foo/a\tb", "/foo/new\nline", "/foo/last"}, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := readPathList(strings.NewReader(""), true)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
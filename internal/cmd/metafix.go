@@ -3,12 +3,18 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/posix"
 	"github.com/umlx5h/gtrash/internal/trash"
 	"github.com/umlx5h/gtrash/internal/tui"
+	"github.com/umlx5h/gtrash/internal/xdg"
 )
 
 type metafixCmd struct {
@@ -17,7 +23,27 @@ type metafixCmd struct {
 }
 
 type metafixOptions struct {
-	force bool
+	force  bool
+	dryRun bool
+
+	orphanFiles string // delete, adopt or skip
+	adoptDir    string
+}
+
+var orphanFilesActions = []string{"delete", "adopt", "skip"}
+
+func (o *metafixOptions) check() error {
+	if o.orphanFiles != "" && !slices.Contains(orphanFilesActions, o.orphanFiles) {
+		return fmt.Errorf("--orphan-files must be %s", strings.Join(orphanFilesActions, "|"))
+	}
+
+	dir, err := filepath.Abs(o.adoptDir) // current directory if empty
+	if err != nil {
+		return fmt.Errorf("--adopt-dir: %w", err)
+	}
+	o.adoptDir = dir
+
+	return nil
 }
 
 func newMetafixCmd() *metafixCmd {
@@ -30,7 +56,20 @@ func newMetafixCmd() *metafixCmd {
   This command is useful after manually removing files in the Trash directory.
   Refer below for detailed information.
 
-  https://github.com/umlx5h/gtrash#what-does-the-metafix-subcommand-do`,
+  https://github.com/umlx5h/gtrash#what-does-the-metafix-subcommand-do
+
+  Files in the Trash directory without meta-information are also detected.
+  They are invisible to other commands and never pruned, so they can be deleted or adopted.
+  Adopted files are given new meta-information, with the original path under --adopt-dir
+  and the deletion date from the modification time, then they can be restored.`,
+		Example: `  # Fix interactively
+  $ gtrash metafix
+
+  # Delete invalid metadata and files without metadata (e.g. from cron)
+  $ gtrash metafix --force --orphan-files delete
+
+  # Make files without metadata restorable to ~/recovered
+  $ gtrash metafix --orphan-files adopt --adopt-dir ~/recovered`,
 		SilenceUsage:      true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
@@ -47,48 +86,171 @@ func newMetafixCmd() *metafixCmd {
 
 	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always execute without confirmation prompt
 This is not necessary if running outside of a terminal`)
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage)
+	cmd.Flags().StringVar(&root.opts.orphanFiles, "orphan-files", "", `How to fix files without metadata: delete|adopt|skip
+By default, prompted in a terminal and skipped otherwise`)
+	cmd.Flags().StringVar(&root.opts.adoptDir, "adopt-dir", "", `Directory of the original path given to adopted files (default: current directory)`)
+
+	noConfig(cmd.Flags(), "force")
+
+	if err := cmd.RegisterFlagCompletionFunc("orphan-files", trash.FlagCompletionFunc(orphanFilesActions)); err != nil {
+		panic(err)
+	}
 
 	root.cmd = cmd
 	return root
 }
 
 func metafixCmdRun(opts metafixOptions) error {
+	if err := opts.check(); err != nil {
+		return err
+	}
+
 	box := trash.NewBox(
 		trash.WithSortBy(trash.SortByName),
 	)
 	if err := box.Open(); err != nil {
-		if errors.Is(err, trash.ErrNotFound) {
+		if !errors.Is(err, trash.ErrNotFound) {
+			return err
+		}
+		// there may be only invalid metadata or orphaned files
+		if len(box.OrphanMeta) == 0 && len(box.OrphanFiles) == 0 {
 			fmt.Printf("do nothing: %s\n", err)
 			return nil
-		} else {
-			return err
 		}
 	}
 
 	if len(box.OrphanMeta) == 0 {
 		fmt.Println("not found invalid metadata")
+	} else {
+		if err := fixOrphanMeta(box.OrphanMeta, opts); err != nil {
+			if len(box.OrphanFiles) == 0 {
+				return err
+			}
+			fmt.Println(err)
+		}
+	}
+
+	if len(box.OrphanFiles) == 0 {
+		fmt.Println("not found files without metadata")
 		return nil
 	}
 
-	listFiles(box.OrphanMeta, false, false)
+	fmt.Println("")
+	return fixOrphanFiles(box.OrphanFiles, opts)
+}
+
+// Delete .trashinfo without corresponding files
+func fixOrphanMeta(files []trash.File, opts metafixOptions) error {
+	listFiles(files, false, false)
 
-	// TODO: Add functionality to allow deletion of orphaned files as well
-	// (those for which trashinfo exists but the file does not).
-	fmt.Printf("\nFound invalid metadata: %d\n", len(box.OrphanMeta))
+	fmt.Printf("\nFound invalid metadata: %d\n", len(files))
+
+	if opts.dryRun {
+		fmt.Printf("Would delete invalid metadata: %d (dry-run)\n", len(files))
+		return nil
+	}
 
 	if !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove invalid metadata? ") {
 		return errors.New("do nothing")
 	}
 
 	var failed int
-	for _, f := range box.OrphanMeta {
+	for _, f := range files {
 		if err := os.Remove(f.TrashInfoPath); err != nil {
 			failed++
 			glog.Errorf("cannot remove .trashinfo: %q: %s\n", f.TrashInfoPath, err)
 		}
 	}
 
-	fmt.Printf("Deleted invalid metadata: %d\n", len(box.OrphanMeta)-failed)
+	fmt.Printf("Deleted invalid metadata: %d\n", len(files)-failed)
+
+	return nil
+}
+
+// Delete or adopt files without .trashinfo
+func fixOrphanFiles(files []trash.File, opts metafixOptions) error {
+	// OriginalPath is unknown, show the path in the trash can instead
+	display := make([]trash.File, len(files))
+	for i, f := range files {
+		f.OriginalPath = f.TrashPath
+		if size, err := posix.DirSizeFallback(f.TrashPath); err == nil {
+			f.Size = &size
+		}
+		display[i] = f
+	}
+	listFiles(display, true, false)
+
+	fmt.Printf("\nFound files without metadata: %d\n", len(files))
+
+	action := opts.orphanFiles
+	if action == "" {
+		switch {
+		case opts.dryRun:
+			fmt.Println("Specify --orphan-files to see what would be done (dry-run)")
+			return nil
+		case !isTerminal:
+			fmt.Println("skipped: specify --orphan-files to delete or adopt them")
+			return nil
+		}
+
+		prompt := fmt.Sprintf("Delete PERMANENTLY, or adopt them as trashed from %q? ", opts.adoptDir)
+		selected, err := tui.ChoicePrompt(prompt, []string{"delete", "adopt", "skip"})
+		if err != nil {
+			return err
+		}
+		action = selected
+	} else if action == "delete" && !opts.dryRun && !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
+		return errors.New("do nothing")
+	}
+
+	switch action {
+	case "delete":
+		if opts.dryRun {
+			fmt.Printf("Would delete files without metadata: %d (dry-run)\n", len(files))
+			return nil
+		}
+
+		var failed int
+		for _, f := range files {
+			slog.Debug("removing a file without metadata", "path", f.TrashPath)
+			if err := os.RemoveAll(f.TrashPath); err != nil {
+				failed++
+				glog.Errorf("cannot remove %q: %s\n", f.TrashPath, err)
+			}
+		}
+		fmt.Printf("Deleted files without metadata: %d\n", len(files)-failed)
+
+	case "adopt":
+		var adopted int
+		for _, f := range files {
+			info := xdg.Info{
+				Path:         filepath.Join(opts.adoptDir, f.Name),
+				DeletionDate: f.DeletedAt,
+			}
+			if opts.dryRun {
+				fmt.Printf("would adopt %q as %q\n", f.TrashPath, info.Path)
+				adopted++
+				continue
+			}
+
+			slog.Debug("saving .trashinfo for a file without metadata", "path", f.TrashPath, "originalPath", info.Path)
+			if err := info.SaveAs(xdg.NewTrashDirManual(f.TrashDir), f.Name); err != nil {
+				glog.Errorf("cannot adopt %q: save trashinfo: %s\n", f.TrashPath, err)
+				continue
+			}
+			adopted++
+		}
+
+		if opts.dryRun {
+			fmt.Printf("Would adopt files without metadata: %d (dry-run)\n", adopted)
+		} else {
+			fmt.Printf("Adopted files without metadata: %d\nThey can be restored to %q by 'gtrash restore'\n", adopted, opts.adoptDir)
+		}
+
+	case "skip":
+		fmt.Println("skipped files without metadata")
+	}
 
 	return nil
 }
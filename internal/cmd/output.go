@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/umlx5h/gtrash/internal/trash"
+	"golang.org/x/exp/maps"
+)
+
+// --output, -o
+
+var _ pflag.Value = (*outputType)(nil)
+
+type outputType int
+
+const (
+	outputText   outputType = iota // default
+	outputJSON                     // one JSON document
+	outputNDJSON                   // one JSON object per line
+)
+
+var (
+	outputWellKnownStrings = map[string]outputType{
+		"text":   outputText,
+		"json":   outputJSON,
+		"ndjson": outputNDJSON,
+	}
+
+	outputFlagCompletionFunc = trash.FlagCompletionFunc(
+		maps.Keys(outputWellKnownStrings),
+	)
+)
+
+func (o *outputType) Set(str string) error {
+	if value, ok := outputWellKnownStrings[strings.ToLower(str)]; ok {
+		*o = value
+		return nil
+	}
+
+	return fmt.Errorf("must be %s", o.Type())
+}
+
+func (o outputType) String() string {
+	switch o {
+	case outputText:
+		return "text"
+	case outputJSON:
+		return "json"
+	case outputNDJSON:
+		return "ndjson"
+	default:
+		panic("invalid outputType value")
+	}
+}
+
+func (o outputType) Type() string {
+	return "text|json|ndjson"
+}
+
+func (o outputType) isJSON() bool {
+	return o == outputJSON || o == outputNDJSON
+}
+
+const outputFlagUsage = `Output format
+text (default):
+    Human readable table (TAB separated when not a terminal)
+
+json:
+    One JSON document
+
+ndjson:
+    One JSON object per line
+
+The schema is described in doc/output.md`
+
+// The JSON schemas below are part of the public interface.
+// Do not rename or remove fields, only add new ones.
+// ref: doc/output.md
+
+type fileJSON struct {
+	Name          string  `json:"name"`
+	OriginalPath  string  `json:"original_path"`
+	TrashPath     string  `json:"trash_path"`
+	TrashInfoPath string  `json:"trash_info_path"`
+	TrashDir      string  `json:"trash_dir"`
+	DeletedAt     string  `json:"deleted_at"` // RFC3339
+	IsDir         bool    `json:"is_dir"`
+	Size          *int64  `json:"size"` // null if not obtained
+	Mode          *string `json:"mode"` // null if lstat(2) failed, same notation as ls -l (e.g. -rw-r--r--)
+}
+
+func newFileJSON(f trash.File) fileJSON {
+	j := fileJSON{
+		Name:          f.Name,
+		OriginalPath:  f.OriginalPath,
+		TrashPath:     f.TrashPath,
+		TrashInfoPath: f.TrashInfoPath,
+		TrashDir:      f.TrashDir,
+		DeletedAt:     f.DeletedAt.Format(time.RFC3339),
+		IsDir:         f.IsDir,
+		Size:          f.Size,
+	}
+
+	// Mode is obtained by lstat(2) when loading only if needed
+	mode := f.Mode
+	if mode == 0 {
+		if fi, err := os.Lstat(f.TrashPath); err == nil {
+			mode = fi.Mode()
+		}
+	}
+	if mode != 0 {
+		m := mode.String()
+		j.Mode = &m
+	}
+
+	return j
+}
+
+func newFilesJSON(files []trash.File) []fileJSON {
+	// must be an empty array instead of null
+	js := make([]fileJSON, len(files))
+	for i, f := range files {
+		js[i] = newFileJSON(f)
+	}
+	return js
+}
+
+type summaryJSON struct {
+	TrashDirs []trashDirSummaryJSON `json:"trash_dirs"`
+	Total     summaryTotalJSON      `json:"total"`
+}
+
+type trashDirSummaryJSON struct {
+	TrashDir string `json:"trash_dir"`
+	Items    int    `json:"items"`
+	Size     int64  `json:"size"` // byte
+}
+
+type summaryTotalJSON struct {
+	Items int   `json:"items"`
+	Size  int64 `json:"size"` // byte
+}
+
+// The last line of ndjson, distinguished from trash dirs by the key
+type summaryTotalLineJSON struct {
+	Total summaryTotalJSON `json:"total"`
+}
+
+type pruneJSON struct {
+	TrashDir string     `json:"trash_dir"`
+	Files    []fileJSON `json:"files"`  // selected to be pruned
+	Failed   []fileJSON `json:"failed"` // could not be removed
+}
+
+// Write v as one JSON document, or elements of v one per line when ndjson
+func writeJSON[T any](w io.Writer, output outputType, v []T) error {
+	if output == outputNDJSON {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, e := range v {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if v == nil {
+		// must be an empty array instead of null
+		v = []T{}
+	}
+
+	return writeJSONDocument(w, v)
+}
+
+// Write v as one indented JSON document, used by writeJSON and for objects (e.g. summary)
+func writeJSONDocument(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
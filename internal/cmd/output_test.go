@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+func TestWriteJSON(t *testing.T) {
+	files := []trash.File{
+		{
+			Name:          "a\tb",
+			OriginalPath:  "/home/user/a\tb",
+			TrashPath:     "/home/user/.local/share/Trash/files/a\tb",
+			TrashInfoPath: "/home/user/.local/share/Trash/info/a\tb.trashinfo",
+			TrashDir:      "/home/user/.local/share/Trash",
+			DeletedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:          "dir",
+			OriginalPath:  "/home/user/dir",
+			TrashPath:     "/home/user/.local/share/Trash/files/dir",
+			TrashInfoPath: "/home/user/.local/share/Trash/info/dir.trashinfo",
+			TrashDir:      "/home/user/.local/share/Trash",
+			DeletedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			IsDir:         true,
+			Size:          newInt(4096),
+			Mode:          fs.ModeDir | 0o755,
+		},
+	}
+
+	t.Run("ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeJSON(&buf, outputNDJSON, newFilesJSON(files)))
+
+		want := `{"name":"a\tb","original_path":"/home/user/a\tb","trash_path":"/home/user/.local/share/Trash/files/a\tb","trash_info_path":"/home/user/.local/share/Trash/info/a\tb.trashinfo","trash_dir":"/home/user/.local/share/Trash","deleted_at":"2024-01-01T00:00:00Z","is_dir":false,"size":null,"mode":null}
+{"name":"dir","original_path":"/home/user/dir","trash_path":"/home/user/.local/share/Trash/files/dir","trash_info_path":"/home/user/.local/share/Trash/info/dir.trashinfo","trash_dir":"/home/user/.local/share/Trash","deleted_at":"2024-01-01T00:00:00Z","is_dir":true,"size":4096,"mode":"drwxr-xr-x"}
+`
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("mode is obtained by lstat if not loaded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(path, nil, 0o640))
+
+		j := newFileJSON(trash.File{TrashPath: path})
+		require.NotNil(t, j.Mode)
+		assert.Equal(t, "-rw-r-----", *j.Mode)
+	})
+
+	t.Run("empty json should be array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeJSON[fileJSON](&buf, outputJSON, nil))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("empty ndjson should be nothing", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeJSON[fileJSON](&buf, outputNDJSON, nil))
+		assert.Empty(t, buf.String())
+	})
+}
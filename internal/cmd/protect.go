@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/umlx5h/gtrash/internal/xdg"
+)
+
+// Trash can directories under mountpoints, see xdg.useExternalTrash
+var externalTrashNameRe = regexp.MustCompile(`^\.Trash(-\d+)?$`)
+
+type protectPattern struct {
+	pattern  string
+	glob     glob.Glob
+	baseOnly bool // match against the base name
+}
+
+// Paths 'put' refuses to trash, built-in ones and [protect] in the config
+type protector struct {
+	home      string
+	homeTrash string
+	patterns  []protectPattern
+}
+
+func newProtector(paths []string) (*protector, error) {
+	p := &protector{
+		homeTrash: filepath.Clean(xdg.DirHomeTrash),
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		p.home = filepath.Clean(home)
+	}
+
+	for _, path := range paths {
+		g, err := glob.Compile(path, '/')
+		if err != nil {
+			// already checked when loaded
+			return nil, fmt.Errorf("protect path %q is invalid glob: %w", path, err)
+		}
+		p.patterns = append(p.patterns, protectPattern{
+			pattern:  path,
+			glob:     g,
+			baseOnly: !strings.Contains(path, "/"),
+		})
+	}
+
+	return p, nil
+}
+
+// Returns the reason if the absolute path is protected
+func (p *protector) check(path string, st fs.FileInfo) (reason string, protected bool) {
+	switch {
+	case path == string(os.PathSeparator):
+		return "root directory", true
+	case filepath.Dir(path) == string(os.PathSeparator):
+		return "top-level directory", true
+	case p.home != "" && isAncestorOrSelf(path, p.home):
+		return "home directory or its parent", true
+	case isAncestorOrSelf(path, p.homeTrash) || isAncestorOrSelf(p.homeTrash, path):
+		return "trash can", true
+	}
+
+	for dir := path; dir != string(os.PathSeparator); dir = filepath.Dir(dir) {
+		if externalTrashNameRe.MatchString(filepath.Base(dir)) {
+			return "trash can", true
+		}
+	}
+
+	// the symlink itself is trashed, not the target
+	if st.Mode().Type() != fs.ModeSymlink {
+		mounted, err := xdg.IsMountpoint(path)
+		if err != nil {
+			slog.Debug("cannot check mountpoint", "path", path, "error", err)
+		} else if mounted {
+			return "mountpoint", true
+		}
+	}
+
+	for _, pat := range p.patterns {
+		target := path
+		if pat.baseOnly {
+			target = filepath.Base(path)
+		}
+		if pat.glob.Match(target) {
+			return fmt.Sprintf("matched %q in [protect] of the config", pat.pattern), true
+		}
+	}
+
+	return "", false
+}
+
+// Whether path is dir or its parent
+func isAncestorOrSelf(path string, dir string) bool {
+	return path == dir || strings.HasPrefix(dir, path+string(os.PathSeparator))
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectorCheck(t *testing.T) {
+	tmp := t.TempDir()
+	home := filepath.Join(tmp, "home", "user")
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "repo", ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, "file"), nil, 0o644))
+	require.NoError(t, os.Symlink("/", filepath.Join(home, "link")))
+
+	p, err := newProtector([]string{".git", filepath.Join(home, "keep", "**")})
+	require.NoError(t, err)
+	p.home = home
+	p.homeTrash = filepath.Join(home, ".local", "share", "Trash")
+
+	st, err := os.Lstat(filepath.Join(home, "file"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path      string
+		protected bool
+	}{
+		{"/", true},
+		{"/etc", true},
+		{home, true},
+		{filepath.Dir(home), true},
+		{filepath.Join(home, "file"), false},
+		{filepath.Join(home, "repo"), false},
+		{filepath.Join(home, "repo", ".git"), true},
+		{filepath.Join(home, "keep", "a"), true},
+		{filepath.Join(home, ".local", "share"), true},
+		{filepath.Join(home, ".local", "share", "Trash", "files", "a"), true},
+		{"/mnt/disk/.Trash-1000", true},
+		{"/mnt/disk/.Trash/1000/files/a", true},
+		{"/mnt/disk/.Trashcan", false},
+	}
+
+	for _, tt := range tests {
+		reason, protected := p.check(tt.path, st)
+		assert.Equal(t, tt.protected, protected, tt.path)
+		if protected {
+			assert.NotEmpty(t, reason, tt.path)
+		}
+	}
+
+	// the symlink itself is trashed, not the mountpoint
+	st, err = os.Lstat(filepath.Join(home, "link"))
+	require.NoError(t, err)
+	_, protected := p.check(filepath.Join(home, "link"), st)
+	assert.False(t, protected)
+}
@@ -3,10 +3,18 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	"github.com/umlx5h/gtrash/internal/env"
 	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/hook"
+	"github.com/umlx5h/gtrash/internal/posix"
 	"github.com/umlx5h/gtrash/internal/trash"
 	"github.com/umlx5h/gtrash/internal/tui"
 )
@@ -17,17 +25,53 @@ type pruneCmd struct {
 }
 
 type pruneOptions struct {
-	force bool
-
-	day  int
-	size string // human size (e.g. 10MB, 1G)
-
-	maxTotalSize uint64 // byte, parse from size
+	force  bool
+	dryRun bool
+
+	day     int
+	since   trash.TimeFlag
+	until   trash.UntilFlag
+	size    string // human size (e.g. 10MB, 1G)
+	minFree string // percentage or human size (e.g. 10%, 20GB)
+	policy  bool
+
+	keep     int
+	maxItems int
+
+	types []string
+	exts  []string
+	owner string
+	where string
+
+	before         time.Time // from day or until, zero is unlimited
+	maxTotalSize   uint64    // byte, parse from size
+	minFreeBytes   uint64    // byte, parse from minFree
+	minFreePercent float64
+	policies       []prunePolicy
 
 	trashDir string // $HOME/.local/share/Trash
+
+	output outputType
 }
 
 func (o *pruneOptions) check() error {
+	if o.keep < 0 || o.maxItems < 0 {
+		return errors.New("--keep and --max-items must not be negative")
+	}
+
+	o.before = o.until.Time
+	if o.day > 0 {
+		o.before = time.Now().AddDate(0, 0, -o.day)
+	}
+
+	if o.policy {
+		policies, err := compilePolicies(env.Config.Policies)
+		if err != nil {
+			return fmt.Errorf("--policy: %w", err)
+		}
+		o.policies = policies
+	}
+
 	if o.size != "" {
 		byte, err := humanize.ParseBytes(o.size)
 		if err != nil {
@@ -35,17 +79,45 @@ func (o *pruneOptions) check() error {
 		}
 		o.maxTotalSize = byte
 	}
+
+	if o.minFree != "" {
+		if p, ok := strings.CutSuffix(o.minFree, "%"); ok {
+			percent, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil || percent <= 0 || percent > 100 {
+				return fmt.Errorf("--min-free percentage must be between 0 and 100: %q", o.minFree)
+			}
+			o.minFreePercent = percent
+		} else {
+			byte, err := humanize.ParseBytes(o.minFree)
+			if err != nil {
+				return fmt.Errorf("--min-free unit is invalid: %w", err)
+			}
+			o.minFreeBytes = byte
+		}
+	}
+
 	return nil
 }
 
+// Returns the free space required on the file system of total size
+func (o *pruneOptions) minFreeTarget(total uint64) uint64 {
+	if o.minFreePercent > 0 {
+		return uint64(float64(total) * o.minFreePercent / 100)
+	}
+	return o.minFreeBytes
+}
+
 func newPruneCmd() *pruneCmd {
 	root := &pruneCmd{}
 	cmd := &cobra.Command{
 		Use:   "prune",
-		Short: "Prune trash cans by day or size",
+		Short: "Prune trash cans by day, size or policies",
 		Long: `Description:
-  Pruning trash cans by day or size criteria.
-  Either the --day or --size option is required.
+  Pruning trash cans by day, size, free space or number of files criteria.
+  Either the --day, --until, --size, --min-free, --keep, --max-items or --policy option is required.
+
+  With --policy, retention policies defined by [[policy]] in the config file are evaluated together.
+  See doc/configuration.md for details.
 
   This command is also intended for use via cron.
   By default, you may be prompted multiple times for each trash can.
@@ -54,6 +126,9 @@ func newPruneCmd() *pruneCmd {
 		Example: `  # Delete all files deleted a week ago
   $ gtrash prune --day 7
 
+  # Delete all files deleted more than 12 hours ago, but not before 2024-01-01
+  $ gtrash prune --until 12h --since 2024-01-01
+
   # Delete all files deleted a week ago only within $HOME trash
   $ gtrash prune --day 7 --trash-dir "$HOME/.local/share/Trash"
 
@@ -63,11 +138,37 @@ func newPruneCmd() *pruneCmd {
 
   # Delete large files first to keep the total remaining size under 5GB, while excluding files deleted in the last week.
   # Note that adding the most recently deleted files may exceed 5GB.
-  $ gtrash prune --size 5GB --day 7`,
+  $ gtrash prune --size 5GB --day 7
+
+  # Delete files in order from the largest until the file system of each trash can has 10% free space
+  $ gtrash prune --min-free 10%
+
+  # Keep only the 100 most recently deleted files in each trash can
+  $ gtrash prune --keep 100
+
+  # Delete the oldest files so that each trash can has at most 1000 files, while excluding files deleted in the last week.
+  $ gtrash prune --max-items 1000 --day 7
+
+  # Delete only log files deleted a week ago
+  $ gtrash prune --day 7 --ext log
+
+  # Show files which would be pruned without removing them
+  $ gtrash prune --size 5GB --dry-run
+
+  # Delete files by retention policies in the config file
+  $ gtrash prune --policy
+
+  # Report pruned files as JSON (e.g. from cron)
+  $ gtrash prune --day 30 --output json`,
 		SilenceUsage:      true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// cannot use MarkFlagsOneRequired because defaults may be given by the config file
+			if root.opts.day == 0 && root.opts.until.Time.IsZero() && root.opts.size == "" && root.opts.minFree == "" && root.opts.keep == 0 && root.opts.maxItems == 0 && !root.opts.policy &&
+				!cmd.Flags().Changed("day") && !cmd.Flags().Changed("keep") && !cmd.Flags().Changed("max-items") {
+				return errors.New("either --day, --until, --size, --min-free, --keep, --max-items or --policy is required")
+			}
 			if err := pruneCmdRun(root.opts); err != nil {
 				return err
 			}
@@ -86,26 +187,167 @@ If you want to delete files larger than the specified size, use the "find --size
 
 Can be specified in human format (e.g. 5MB, 1GB)
 
-If --day and --size are specified at the same time, the most recent X days are excluded from the calculation.
+If --day (or --until) and --size are specified at the same time, the most recent X days are excluded from the calculation.
 This may be useful when you do not want to delete large files that have been recently deleted.
+`)
+	cmd.Flags().StringVar(&root.opts.minFree, "min-free", "", `Remove files in order from the largest to the smaller one until the file system containing the trash can has the specified free space.
+If the free space is already larger than the specified size, nothing is done.
+The free space is checked by each file system, trash cans sharing it are pruned together to reach it.
+
+Can be specified in percentage of the file system size (e.g. 10%) or human format (e.g. 20GB)
+
+If --day (or --until) is specified at the same time, the most recent X days are excluded, same as --size.
+`)
+	cmd.Flags().IntVar(&root.opts.keep, "keep", 0, `Remove files except the N most recently deleted ones in each trash can
+
+If --day (or --until) is specified at the same time, the most recent X days are excluded, and N files are kept in addition to them.
+`)
+	cmd.Flags().IntVar(&root.opts.maxItems, "max-items", 0, `Remove the oldest files so that the number of files in each trash can is N or less
+
+If --day (or --until) is specified at the same time, files deleted in the most recent X days are not removed, but are counted.
 `)
 	cmd.Flags().IntVar(&root.opts.day, "day", 0, "Remove all files deleted before X days")
+	cmd.Flags().Var(&root.opts.until, "until", `Remove all files deleted at or before this
+Same as --day, but other notations are accepted`+timeFlagUsage+`
+A date without time includes the whole day`)
+	cmd.Flags().Var(&root.opts.since, "since", `Only files deleted at or after this are pruned
+Can be combined with other options to limit the range`+timeFlagUsage)
+	addAttrFilterFlags(cmd, &root.opts.types, &root.opts.exts, &root.opts.owner)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
+	cmd.Flags().BoolVar(&root.opts.policy, "policy", false, `Remove files by retention policies defined by [[policy]] in the config file
+Cannot be used with --day and --size`)
 
 	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always execute without confirmation prompt
 This is not necessary if running outside of a terminal
 `)
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage)
 	cmd.Flags().StringVar(&root.opts.trashDir, "trash-dir", "", `Specify a full path if you want to prune only a specific trash can
 By default, all trash cans are pruned.
 
 For $HOME trash only:
     --trash-dir "$HOME/.local/share/Trash"
 `)
-	cmd.Root().MarkFlagsOneRequired("size", "day")
+	cmd.Flags().VarP(&root.opts.output, "output", "o", outputFlagUsage+`
+
+When used in a terminal, --force is required.`)
+	cmd.MarkFlagsMutuallyExclusive("policy", "day")
+	cmd.MarkFlagsMutuallyExclusive("policy", "until")
+	cmd.MarkFlagsMutuallyExclusive("policy", "since")
+	cmd.MarkFlagsMutuallyExclusive("day", "until")
+	cmd.MarkFlagsMutuallyExclusive("max-items", "since")
+	cmd.MarkFlagsMutuallyExclusive("policy", "size")
+	cmd.MarkFlagsMutuallyExclusive("policy", "min-free")
+	cmd.MarkFlagsMutuallyExclusive("size", "min-free")
+	cmd.MarkFlagsMutuallyExclusive("keep", "max-items")
+	for _, f := range []string{"size", "min-free", "policy"} {
+		cmd.MarkFlagsMutuallyExclusive("keep", f)
+		cmd.MarkFlagsMutuallyExclusive("max-items", f)
+	}
+	noConfig(cmd.Flags(), "force")
+
+	if err := cmd.RegisterFlagCompletionFunc("output", outputFlagCompletionFunc); err != nil {
+		panic(err)
+	}
 
 	root.cmd = cmd
 	return root
 }
 
+// Returns files to be deleted except the n most recently deleted files
+// Files deleted after before are never deleted, but are counted. Zero before means no limit.
+//
+// Prerequisite: files are sorted in ascending order by deletion date
+func getCountPruneFiles(files []trash.File, n int, before time.Time) []trash.File {
+	if len(files) <= n {
+		return nil
+	}
+
+	prune := files[:len(files)-n]
+	if !before.IsZero() {
+		for i, f := range prune {
+			if !f.DeletedAt.Before(before) {
+				prune = prune[:i]
+				break
+			}
+		}
+	}
+
+	if len(prune) == 0 {
+		return nil
+	}
+	return prune
+}
+
+// Returns files to be deleted from files so that avail becomes larger than target
+// Larger files are selected first. If avail >= target, nil is returned.
+//
+// Prerequisite: files are sorted in ascending order by size
+func getMinFreePruneFiles(files []trash.File, avail uint64, target uint64) (prune []trash.File, deleted uint64) {
+	if avail >= target {
+		return nil, 0
+	}
+
+	i := len(files)
+	for i > 0 && avail+deleted < target {
+		i--
+		// size unknown files are at the top, not considered for deletion
+		if files[i].Size == nil {
+			i++
+			break
+		}
+		deleted += uint64(*files[i].Size)
+	}
+
+	if i == len(files) {
+		return nil, 0
+	}
+
+	return files[i:], deleted
+}
+
+// Free space of file systems for --min-free
+// Each file system is checked once, and files planned to be pruned in a trash can are counted as freed
+// for the next trash cans on the same file system (e.g. the home trash can and .Trash-$uid),
+// otherwise each of them would prune enough to reach the target by itself.
+type freeSpace struct {
+	disks map[uint64]*disk // by st_dev
+
+	deviceID  func(path string) (uint64, error)
+	diskSpace func(path string) (avail uint64, total uint64, err error)
+}
+
+type disk struct {
+	avail, total uint64 // before pruning
+	planned      uint64 // size of files planned to be pruned
+}
+
+func newFreeSpace() *freeSpace {
+	return &freeSpace{
+		disks:     make(map[uint64]*disk),
+		deviceID:  posix.DeviceID,
+		diskSpace: posix.DiskSpace,
+	}
+}
+
+// Returns the file system containing trashDir, avail includes the planned size
+func (s *freeSpace) get(trashDir string) (*disk, error) {
+	dev, err := s.deviceID(trashDir)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := s.disks[dev]; ok {
+		return d, nil
+	}
+
+	avail, total, err := s.diskSpace(trashDir)
+	if err != nil {
+		return nil, err
+	}
+	d := &disk{avail: avail, total: total}
+	s.disks[dev] = d
+	return d, nil
+}
+
 // Returns files to be deleted from files based on maxTotalSize
 // If maxTotalSize > total, nil is returned.
 //
@@ -144,23 +386,46 @@ func pruneCmdRun(opts pruneOptions) error {
 		return err
 	}
 
+	if opts.output.isJSON() && isTerminal && !opts.force && !opts.dryRun {
+		return errors.New("--output requires --force when running in a terminal")
+	}
+
 	sortMethod := trash.SortByDeletedAt
 
-	sizeMode := opts.size != ""
+	sizeMode := opts.size != "" || opts.minFree != ""
+	for _, p := range opts.policies {
+		if p.maxTotalSize > 0 {
+			sizeMode = true
+		}
+	}
 
-	if opts.size != "" {
+	if opts.size != "" || opts.minFree != "" {
 		sortMethod = trash.SortBySize
 	}
 
+	before := opts.before
+	if opts.policy || opts.maxItems > 0 {
+		// evaluated per file later
+		before = time.Time{}
+	}
+
 	box := trash.NewBox(
 		trash.WithSortBy(sortMethod),
 		trash.WithGetSize(sizeMode),
+		trash.WithGetMode(opts.output.isJSON() || hook.Installed(hook.PostPrune)), // files are output after removal
 		trash.WithAscend(true),
-		trash.WithDay(0, opts.day),
+		trash.WithTimeRange(opts.since.Time, before),
+		trash.WithTypes(opts.types),
+		trash.WithExts(opts.exts),
+		trash.WithOwner(opts.owner),
+		trash.WithWhere(opts.where),
 		trash.WithTrashDir(opts.trashDir),
 	)
 	if err := box.Open(); err != nil {
 		if errors.Is(err, trash.ErrNotFound) {
+			if opts.output.isJSON() {
+				return writeJSON[pruneJSON](os.Stdout, opts.output, nil)
+			}
 			fmt.Printf("do nothing: %s\n", err)
 			return nil
 		} else {
@@ -168,39 +433,131 @@ func pruneCmdRun(opts pruneOptions) error {
 		}
 	}
 
+	var results []pruneJSON
+
+	// for --min-free
+	space := newFreeSpace()
+
+	// passed to post-prune hook
+	var pruned []trash.File
+	defer func() {
+		runPostHook(hook.PostPrune, pruned)
+	}()
+
 	for i, trashDir := range box.TrashDirs {
 		files := box.FilesByTrashDir[trashDir]
 		if len(files) == 0 {
 			continue
 		}
 
-		var deleted, total uint64
+		var (
+			deleted, total uint64
+			counts         map[string]int
+			avail, target  uint64 // free space for --min-free
+		)
+
+		if opts.policy {
+			files, counts = getPolicyPruneFiles(files, trashDir, opts.policies, time.Now())
+			if len(files) == 0 {
+				if !opts.output.isJSON() {
+					fmt.Printf("do nothing: no files to be pruned by policies in %s\n", trashDir)
+				}
+				continue
+			}
+		} else if opts.keep > 0 || opts.maxItems > 0 {
+			n, before := opts.keep, time.Time{}
+			if opts.maxItems > 0 {
+				n = opts.maxItems
+				before = opts.before
+			}
 
-		if sizeMode {
+			files = getCountPruneFiles(files, n, before)
+			if len(files) == 0 {
+				if !opts.output.isJSON() {
+					fmt.Printf("do nothing: number of files is %d or less in %s\n", n, trashDir)
+				}
+				continue
+			}
+		} else if opts.minFree != "" {
+			d, err := space.get(trashDir)
+			if err != nil {
+				glog.Errorf("cannot check free space of %q: %s\n", trashDir, err)
+				continue
+			}
+			avail = d.avail + d.planned
+			target = opts.minFreeTarget(d.total)
+
+			files, deleted = getMinFreePruneFiles(files, avail, target)
+			d.planned += deleted
+			if len(files) == 0 {
+				if opts.output.isJSON() {
+					continue
+				}
+				if avail >= target {
+					fmt.Printf("do nothing: free space %s is larger than %s (%s) in %s\n", humanize.Bytes(avail), humanize.Bytes(target), opts.minFree, trashDir)
+				} else {
+					fmt.Printf("do nothing: no files to be pruned in %s\n", trashDir)
+				}
+				continue
+			}
+			if deleted+avail < target {
+				slog.Warn("free space will be less than specified even if pruned", "trashDir", trashDir, "free", humanize.Bytes(avail+deleted), "specified", humanize.Bytes(target))
+			}
+		} else if sizeMode {
 			files, deleted, total = getPruneFiles(files, opts.maxTotalSize)
 			if len(files) == 0 {
+				if opts.output.isJSON() {
+					continue
+				}
 				fmt.Printf("do nothing: trash size %s is smaller than %s (%s) in %s\n", humanize.Bytes(total), humanize.Bytes(opts.maxTotalSize), opts.size, trashDir)
 				continue
 			}
 		}
 
+		if opts.output.isJSON() {
+			var removed, failed []trash.File
+			if !opts.dryRun {
+				removed, failed = removeFiles(files)
+				pruned = append(pruned, removed...)
+			}
+			results = append(results, pruneJSON{
+				TrashDir: trashDir,
+				Files:    newFilesJSON(files),
+				Failed:   newFilesJSON(failed),
+			})
+			continue
+		}
+
 		listFiles(files, sizeMode, false)
 
 		fmt.Printf("\nSelected %d files in %s\n", len(files), trashDir)
 
-		if sizeMode {
+		if opts.policy {
+			for _, p := range opts.policies {
+				if n := counts[p.name]; n > 0 {
+					fmt.Printf("Policy %s: %d files\n", p.name, n)
+				}
+			}
+			fmt.Println("")
+		} else if opts.minFree != "" {
+			fmt.Printf("Free: %s, Deleted: %s, After: %s, Specified: %s (%s)\n\n", humanize.Bytes(avail), humanize.Bytes(deleted), humanize.Bytes(avail+deleted), humanize.Bytes(target), opts.minFree)
+		} else if sizeMode {
 			fmt.Printf("Current: %s, Deleted: %s, After: %s, Specified: %s\n\n", humanize.Bytes(total), humanize.Bytes(deleted), humanize.Bytes(total-deleted), humanize.Bytes(opts.maxTotalSize))
 		}
 
-		if !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
+		if !opts.dryRun && !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
 			return errors.New("do nothing")
 		}
-		doRemove(files)
+		pruned = append(pruned, doRemove(files, opts.dryRun)...)
 
 		if i != len(box.TrashDirs)-1 {
 			fmt.Println("")
 		}
 	}
 
+	if opts.output.isJSON() {
+		return writeJSON(os.Stdout, opts.output, results)
+	}
+
 	return nil
 }
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gobwas/glob"
+	"github.com/umlx5h/gtrash/internal/config"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+// Retention policy compiled from config.Policy
+type prunePolicy struct {
+	name string
+
+	trashDir string    // empty matches all
+	path     glob.Glob // nil matches all
+	baseOnly bool      // match path against the base name
+
+	day          int
+	maxTotalSize uint64 // byte, 0 is unlimited
+	keep         int
+}
+
+func compilePolicies(policies []config.Policy) ([]prunePolicy, error) {
+	if len(policies) == 0 {
+		return nil, errors.New("no [[policy]] is defined in the config file")
+	}
+
+	compiled := make([]prunePolicy, len(policies))
+	for i, p := range policies {
+		c := prunePolicy{
+			name: p.Name,
+			day:  p.Day,
+			keep: p.Keep,
+		}
+		if c.name == "" {
+			c.name = fmt.Sprintf("#%d", i+1)
+		}
+
+		if p.Day < 0 || p.Keep < 0 {
+			return nil, fmt.Errorf("policy %s: day and keep must not be negative", c.name)
+		}
+		if p.Day == 0 && p.Size == "" && p.Keep == 0 {
+			return nil, fmt.Errorf("policy %s: either day, size or keep is required", c.name)
+		}
+
+		if p.TrashDir != "" {
+			c.trashDir = filepath.Clean(p.TrashDir)
+		}
+
+		if p.Path != "" {
+			g, err := glob.Compile(p.Path, '/')
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: path is invalid glob: %w", c.name, err)
+			}
+			c.path = g
+			// e.g. *.iso matches files in any directory
+			c.baseOnly = !strings.Contains(p.Path, "/")
+		}
+
+		if p.Size != "" {
+			size, err := humanize.ParseBytes(p.Size)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: size unit is invalid: %w", c.name, err)
+			}
+			c.maxTotalSize = size
+		}
+
+		compiled[i] = c
+	}
+
+	return compiled, nil
+}
+
+func (p prunePolicy) match(f trash.File) bool {
+	if p.path == nil {
+		return true
+	}
+	if p.baseOnly {
+		return p.path.Match(filepath.Base(f.OriginalPath))
+	}
+	return p.path.Match(f.OriginalPath)
+}
+
+// Returns files to be deleted in trashDir by policies, and the number of files for each policy
+//
+//  1. The most recent keep files matched are protected from all policies.
+//  2. Files older than day are deleted. The first policy matched with day is used for each file,
+//     so a more specific policy should be defined first.
+//  3. Larger files are deleted first until the total size of files matched is less than size.
+//
+// Prerequisite: files are sorted in ascending order by deletion date
+func getPolicyPruneFiles(files []trash.File, trashDir string, policies []prunePolicy, now time.Time) (prune []trash.File, counts map[string]int) {
+	var applied []prunePolicy
+	for _, p := range policies {
+		if p.trashDir == "" || p.trashDir == trashDir {
+			applied = append(applied, p)
+		}
+	}
+
+	protected := make([]bool, len(files))
+	pruned := make([]bool, len(files))
+	counts = make(map[string]int)
+
+	for _, p := range applied {
+		if p.keep == 0 {
+			continue
+		}
+		kept := 0
+		for i := len(files) - 1; i >= 0 && kept < p.keep; i-- {
+			if p.match(files[i]) {
+				protected[i] = true
+				kept++
+			}
+		}
+	}
+
+	for i, f := range files {
+		if protected[i] {
+			continue
+		}
+		for _, p := range applied {
+			if p.day == 0 || !p.match(f) {
+				continue
+			}
+			if f.DeletedAt.Before(now.AddDate(0, 0, -p.day)) {
+				pruned[i] = true
+				counts[p.name]++
+			}
+			break
+		}
+	}
+
+	for _, p := range applied {
+		if p.maxTotalSize == 0 {
+			continue
+		}
+
+		var (
+			total      uint64
+			candidates []int
+		)
+		for i, f := range files {
+			if pruned[i] || f.Size == nil || !p.match(f) {
+				continue
+			}
+			total += uint64(*f.Size)
+			if !protected[i] {
+				candidates = append(candidates, i)
+			}
+		}
+
+		// largest first
+		slices.SortStableFunc(candidates, func(a, b int) int {
+			return cmp.Compare(*files[b].Size, *files[a].Size)
+		})
+
+		for _, i := range candidates {
+			if total <= p.maxTotalSize {
+				break
+			}
+			total -= uint64(*files[i].Size)
+			pruned[i] = true
+			counts[p.name]++
+		}
+	}
+
+	for i, f := range files {
+		if pruned[i] {
+			prune = append(prune, f)
+		}
+	}
+
+	return prune, counts
+}
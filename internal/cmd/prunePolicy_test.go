@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/config"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+func TestGetPolicyPruneFiles(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time {
+		return now.AddDate(0, 0, -d)
+	}
+
+	// sorted by deletion date
+	files := []trash.File{
+		{Name: "old.iso", OriginalPath: "/home/user/dl/old.iso", DeletedAt: daysAgo(40), Size: newInt(500)},
+		{Name: "main.go", OriginalPath: "/home/user/projects/a/main.go", DeletedAt: daysAgo(35), Size: newInt(10)},
+		{Name: "new.iso", OriginalPath: "/home/user/projects/new.iso", DeletedAt: daysAgo(10), Size: newInt(400)},
+		{Name: "note.txt", OriginalPath: "/home/user/note.txt", DeletedAt: daysAgo(8), Size: newInt(300)},
+		{Name: "big.bin", OriginalPath: "/home/user/big.bin", DeletedAt: daysAgo(2), Size: newInt(200)},
+		{Name: "x.txt", OriginalPath: "/home/user/projects/x.txt", DeletedAt: daysAgo(1), Size: newInt(100)},
+	}
+
+	names := func(files []trash.File) []string {
+		var n []string
+		for _, f := range files {
+			n = append(n, f.Name)
+		}
+		return n
+	}
+
+	compile := func(t *testing.T, policies ...config.Policy) []prunePolicy {
+		t.Helper()
+		p, err := compilePolicies(policies)
+		require.NoError(t, err)
+		return p
+	}
+
+	t.Run("first policy matched with day is used", func(t *testing.T) {
+		policies := compile(t,
+			config.Policy{Name: "iso", Path: "*.iso", Day: 7},
+			config.Policy{Name: "projects", Path: "/home/user/projects/**", Day: 30},
+		)
+		got, counts := getPolicyPruneFiles(files, "/trash", policies, now)
+		assert.Equal(t, []string{"old.iso", "main.go", "new.iso"}, names(got))
+		assert.Equal(t, map[string]int{"iso": 2, "projects": 1}, counts)
+	})
+
+	t.Run("size cap deletes larger files first", func(t *testing.T) {
+		policies := compile(t, config.Policy{Name: "cap", Size: "700B"})
+		got, _ := getPolicyPruneFiles(files, "/trash", policies, now)
+		// 1510 -> 1010 -> 610
+		assert.Equal(t, []string{"old.iso", "new.iso"}, names(got))
+	})
+
+	t.Run("size cap counts files after age pruning", func(t *testing.T) {
+		policies := compile(t,
+			config.Policy{Name: "age", Day: 30},
+			config.Policy{Name: "cap", Size: "700B"},
+		)
+		got, counts := getPolicyPruneFiles(files, "/trash", policies, now)
+		// 1000 -> 600
+		assert.Equal(t, []string{"old.iso", "main.go", "new.iso"}, names(got))
+		assert.Equal(t, map[string]int{"age": 2, "cap": 1}, counts)
+	})
+
+	t.Run("keep protects recent files from all policies", func(t *testing.T) {
+		policies := compile(t,
+			config.Policy{Name: "all", Day: 1},
+			config.Policy{Name: "recent", Keep: 3},
+		)
+		got, _ := getPolicyPruneFiles(files, "/trash", policies, now)
+		assert.Equal(t, []string{"old.iso", "main.go", "new.iso"}, names(got))
+	})
+
+	t.Run("policy of other trash dir is ignored", func(t *testing.T) {
+		policies := compile(t, config.Policy{Name: "data", TrashDir: "/mnt/data/.Trash-1000", Day: 1})
+		got, _ := getPolicyPruneFiles(files, "/trash", policies, now)
+		assert.Empty(t, got)
+
+		got, _ = getPolicyPruneFiles(files, "/mnt/data/.Trash-1000", policies, now)
+		assert.Len(t, got, 5)
+	})
+}
+
+func TestCompilePoliciesError(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []config.Policy
+	}{
+		{name: "no policy"},
+		{name: "no rule", policies: []config.Policy{{Name: "a", Path: "*.iso"}}},
+		{name: "invalid size", policies: []config.Policy{{Size: "10XB"}}},
+		{name: "invalid glob", policies: []config.Policy{{Path: "[", Day: 1}}},
+		{name: "negative", policies: []config.Policy{{Day: -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compilePolicies(tt.policies)
+			assert.Error(t, err)
+		})
+	}
+}
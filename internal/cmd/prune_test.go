@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/umlx5h/gtrash/internal/trash"
 )
 
@@ -106,3 +108,115 @@ func TestGetPruneFiles(t *testing.T) {
 	})
 
 }
+
+func TestGetMinFreePruneFiles(t *testing.T) {
+	files := []trash.File{
+		{Name: "unknown"},
+		{Name: "a", Size: newInt(20)},
+		{Name: "b", Size: newInt(30)},
+		{Name: "c", Size: newInt(50)},
+	}
+
+	t.Run("should prune from larger files until target", func(t *testing.T) {
+		got, deleted := getMinFreePruneFiles(files, 100, 160)
+		assert.Equal(t, files[2:], got)
+		assert.EqualValues(t, 80, deleted)
+	})
+
+	t.Run("should return nil if enough free space", func(t *testing.T) {
+		got, deleted := getMinFreePruneFiles(files, 100, 100)
+		assert.Nil(t, got)
+		assert.EqualValues(t, 0, deleted)
+	})
+
+	t.Run("should not include size unknown files", func(t *testing.T) {
+		got, deleted := getMinFreePruneFiles(files, 0, 1000)
+		assert.Equal(t, files[1:], got)
+		assert.EqualValues(t, 100, deleted)
+	})
+}
+
+func TestFreeSpace(t *testing.T) {
+	devs := map[string]uint64{"/home/user/.local/share/Trash": 1, "/home/.Trash-1000": 1, "/mnt/.Trash-1000": 2}
+	statfs := 0
+
+	space := newFreeSpace()
+	space.deviceID = func(path string) (uint64, error) {
+		return devs[path], nil
+	}
+	space.diskSpace = func(path string) (uint64, uint64, error) {
+		statfs++
+		return 100, 1000, nil
+	}
+
+	files := []trash.File{
+		{Name: "a", Size: newInt(20)},
+		{Name: "b", Size: newInt(30)},
+	}
+
+	// first trash can on the file system prunes enough
+	d, err := space.get("/home/user/.local/share/Trash")
+	require.NoError(t, err)
+	got, deleted := getMinFreePruneFiles(files, d.avail+d.planned, 130)
+	assert.Equal(t, files[1:], got)
+	d.planned += deleted
+
+	// the other one on the same file system does nothing
+	d, err = space.get("/home/.Trash-1000")
+	require.NoError(t, err)
+	assert.EqualValues(t, 130, d.avail+d.planned)
+	got, _ = getMinFreePruneFiles(files, d.avail+d.planned, 130)
+	assert.Nil(t, got)
+
+	// another file system
+	d, err = space.get("/mnt/.Trash-1000")
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, d.avail+d.planned)
+
+	assert.Equal(t, 2, statfs, "checked once per file system")
+}
+
+func TestPruneOptionsMinFree(t *testing.T) {
+	t.Run("percentage", func(t *testing.T) {
+		opts := pruneOptions{minFree: "10%"}
+		require.NoError(t, opts.check())
+		assert.EqualValues(t, 100, opts.minFreeTarget(1000))
+	})
+
+	t.Run("human size", func(t *testing.T) {
+		opts := pruneOptions{minFree: "20GB"}
+		require.NoError(t, opts.check())
+		assert.EqualValues(t, 20_000_000_000, opts.minFreeTarget(1000))
+	})
+
+	for _, v := range []string{"0%", "101%", "x%", "20XB"} {
+		t.Run("invalid "+v, func(t *testing.T) {
+			opts := pruneOptions{minFree: v}
+			assert.Error(t, opts.check())
+		})
+	}
+}
+
+func TestGetCountPruneFiles(t *testing.T) {
+	now := time.Now()
+	files := []trash.File{
+		{Name: "a", DeletedAt: now.AddDate(0, 0, -30)},
+		{Name: "b", DeletedAt: now.AddDate(0, 0, -20)},
+		{Name: "c", DeletedAt: now.AddDate(0, 0, -10)},
+		{Name: "d", DeletedAt: now.AddDate(0, 0, -5)},
+		{Name: "e", DeletedAt: now.AddDate(0, 0, -1)},
+	}
+
+	t.Run("should keep n most recent files", func(t *testing.T) {
+		assert.Equal(t, files[:3], getCountPruneFiles(files, 2, time.Time{}))
+	})
+
+	t.Run("should return nil if n or less", func(t *testing.T) {
+		assert.Nil(t, getCountPruneFiles(files, 5, time.Time{}))
+	})
+
+	t.Run("should not prune files deleted after before", func(t *testing.T) {
+		assert.Equal(t, files[:2], getCountPruneFiles(files, 1, now.AddDate(0, 0, -15)))
+		assert.Nil(t, getCountPruneFiles(files, 1, now.AddDate(0, 0, -40)))
+	})
+}
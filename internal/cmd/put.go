@@ -12,10 +12,15 @@ import (
 	"time"
 
 	cp "github.com/otiai10/copy"
+	"github.com/rs/xid"
 	"github.com/spf13/cobra"
 	"github.com/umlx5h/gtrash/internal/env"
 	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/history"
+	"github.com/umlx5h/gtrash/internal/hook"
+	"github.com/umlx5h/gtrash/internal/ignore"
 	"github.com/umlx5h/gtrash/internal/posix"
+	"github.com/umlx5h/gtrash/internal/trash"
 	"github.com/umlx5h/gtrash/internal/tui"
 	"github.com/umlx5h/gtrash/internal/xdg"
 )
@@ -36,13 +41,20 @@ type putOptions struct {
 	dir       bool
 
 	homeFallback bool
+	dryRun       bool
+	iKnow        bool
+	noIgnore     bool
+	ignoreDelete bool
+
+	fromFile string
+	null     bool
 }
 
 func newPutCmd() *putCmd {
 	root := &putCmd{}
 
 	cmd := &cobra.Command{
-		Use:     "put PATH...",
+		Use:     "put [PATH...]",
 		Aliases: []string{"p"},
 		Short:   "Put files to trash (p)",
 		Long: `Description:
@@ -57,6 +69,15 @@ func newPutCmd() *putCmd {
   Folder 1 takes precedence but requires pre-creation with a set sticky bit ($uid part is created automatically).
   Folder 2 is created automatically.
 
+  Protected paths are refused: /, top-level directories, $HOME and its parents, mountpoints, trash cans
+  and paths matching [protect] in the config file. Use --i-know to trash them anyway.
+
+  Files matching ignore rules are removed PERMANENTLY instead of being trashed, after a confirmation.
+  Outside of a terminal, they are left as is unless --ignore-delete is given.
+  With --force, they are trashed as usual because it never prompts.
+  The rules are read from $XDG_CONFIG_HOME/gtrash/ignore and .gtrashignore in the parent directories.
+  Use --no-ignore to trash them.
+
   To identify the folder where files will be moved, use the -v or --debug option.
   To display the path in the trash can, use --show-trashpath with the find command:
       $ gtrash find --show-trashpath
@@ -71,11 +92,20 @@ func newPutCmd() *putCmd {
   $ gtrash put -- -foo
 
   # If expanded in the shell, you can use glob patterns
-  $ gtrash put foo*`,
-		Args:          cobra.MinimumNArgs(1),
+  $ gtrash put foo*
+
+  # Trash files found by find(1), safe for any filename
+  $ find . -name '*.log' -print0 | gtrash put -0`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(_ *cobra.Command, args []string) error {
+			args, err := argsWithPathList(args, root.opts.fromFile, root.opts.null)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return errors.New("requires at least 1 path")
+			}
 			if err := putCmdRun(args, root.opts); err != nil {
 				return err
 			}
@@ -104,6 +134,22 @@ remove directories and their contents recursively (--rm-mode)`)
 	cmd.Flags().BoolVar(&root.opts.homeFallback, "home-fallback", env.HOME_TRASH_FALLBACK_COPY, `Enable fallback to home directory trash
 If the deletion of a file in an external file system fails, this option may help.`)
 
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage+`
+The trash can to be used is displayed`)
+	cmd.Flags().StringVar(&root.opts.fromFile, "from-file", "", fromFileFlagUsage)
+	cmd.Flags().BoolVarP(&root.opts.null, "null", "0", false, nullFlagUsage)
+	cmd.Flags().BoolVar(&root.opts.iKnow, "i-know", false, `Trash protected paths such as $HOME and mountpoints
+Cannot be set in the config file`)
+	cmd.Flags().BoolVar(&root.opts.noIgnore, "no-ignore", false, "Trash files matching ignore rules instead of removing them PERMANENTLY")
+	cmd.Flags().BoolVar(&root.opts.ignoreDelete, "ignore-delete", false, `Remove files matching ignore rules PERMANENTLY without confirmation
+Cannot be set in the config file`)
+
+	cmd.MarkFlagsMutuallyExclusive("no-ignore", "ignore-delete")
+
+	envFlag(cmd.Flags(), "rm-mode", "GTRASH_PUT_RM_MODE")
+	envFlag(cmd.Flags(), "home-fallback", "GTRASH_HOME_TRASH_FALLBACK_COPY")
+	noConfig(cmd.Flags(), "Recursive", "from-file", "null", "i-know", "force", "ignore-delete")
+
 	root.cmd = cmd
 	return root
 }
@@ -112,11 +158,15 @@ func putCmdRun(args []string, opts putOptions) error {
 	if isDebug {
 		opts.verbose = true
 	}
-	if opts.force {
+	if opts.force || opts.dryRun {
 		// If both are specified, force is preferred.
 		opts.prompt = false
 		opts.promptOnce = false
 	}
+	if opts.force && !opts.ignoreDelete {
+		// -f never prompts, so files are not removed PERMANENTLY without --ignore-delete
+		opts.noIgnore = true
+	}
 
 	slog.Debug("starting put", "args", args, "home-fallback", opts.homeFallback, "rm-mode", opts.rmMode)
 
@@ -124,6 +174,16 @@ func putCmdRun(args []string, opts putOptions) error {
 		return errors.New("cannot use -i without tty")
 	}
 
+	protect, err := newProtector(env.Config.Protect.Paths)
+	if err != nil {
+		return err
+	}
+
+	ignores, err := ignore.New(ignore.GlobalPath())
+	if err != nil {
+		return err
+	}
+
 	if opts.promptOnce {
 		// -I confirmation dialog
 		for _, a := range args {
@@ -140,6 +200,17 @@ func putCmdRun(args []string, opts putOptions) error {
 	// could restore-group to work, reuse deleteTime
 	var deleteTime time.Time
 
+	// Record trashed files to be able to undo this invocation.
+	// The ID is also written to .trashinfo as a session so that restore-group can group them exactly.
+	record := history.Record{
+		ID: xid.New().String(),
+	}
+	// passed to post-put hook
+	var trashed []trash.File
+
+	// removed PERMANENTLY after the loop
+	var ignored []ignoredFile
+
 	for _, arg := range args {
 		// same as rm
 		if slices.Contains([]string{".", ".."}, filepath.Base(arg)) {
@@ -160,6 +231,19 @@ func putCmdRun(args []string, opts putOptions) error {
 			continue
 		}
 
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			glog.Errorf("cannot trash %q: get abspath: %s\n", arg, err)
+			continue
+		}
+
+		if !opts.iKnow {
+			if reason, ok := protect.check(path, st); ok {
+				glog.Errorf("cannot trash %q: protected path (%s), use --i-know to trash it anyway\n", arg, reason)
+				continue
+			}
+		}
+
 		if opts.rmMode {
 			if st.IsDir() {
 				if !opts.recursive && !opts.dir {
@@ -201,14 +285,27 @@ func putCmdRun(args []string, opts putOptions) error {
 			}
 		}
 
-		path, err := filepath.Abs(arg)
-		if err != nil {
-			glog.Errorf("cannot trash %q: get abspath: %s\n", arg, err)
-			continue
+		// not trashed, so put hooks are not run
+		if !opts.noIgnore {
+			if source, ok := ignores.Match(path, st.IsDir()); ok {
+				slog.Debug("matched ignore rule", "path", path, "source", source)
+				ignored = append(ignored, ignoredFile{arg: arg, path: path, source: source})
+				continue
+			}
+		}
+
+		if !opts.dryRun {
+			// not trashed yet, so only the original path and attributes are passed
+			if err := runPreHook(hook.PrePut, newPutFile(path, st)); err != nil {
+				glog.Errorf("cannot trash %q: %s\n", arg, err)
+				continue
+			}
 		}
 
-		// for -v logging
-		var usedDir xdg.TrashDir
+		var (
+			usedDir       xdg.TrashDir // for -v logging
+			trashInfoPath string
+		)
 
 		slog.Debug("looking up trash_dir", "path", path)
 
@@ -227,11 +324,25 @@ func putCmdRun(args []string, opts putOptions) error {
 			slog.Debug("fallback to home trash because external trash is not found", "error", err)
 		}
 
+		if opts.dryRun {
+			// rename(2) may still fail on the actual run
+			dir := homeDir
+			if externalDir != nil {
+				dir = externalDir
+			}
+			if dir == nil {
+				glog.Errorf("cannot trash %q: lookup trash directory: not found\n", arg)
+				continue
+			}
+			fmt.Printf("would trash %q to %s\n", arg, posix.AbsPathToTilde(dir.Dir))
+			continue
+		}
+
 		// preferred if an external trash can is available.
 		if externalDir != nil {
 			slog.Debug("will use external trash, will use rename(2) to move", "trashDir", externalDir.Dir)
 			// external trash only uses rename, not copy
-			if err := trashFile(*externalDir, path, &deleteTime, false); err != nil {
+			if trashInfoPath, err = trashFile(*externalDir, path, &deleteTime, record.ID, false); err != nil {
 				if !opts.homeFallback {
 					glog.Errorf("cannot trash %q: %s\n", arg, err)
 					continue
@@ -250,23 +361,115 @@ func putCmdRun(args []string, opts putOptions) error {
 		} else {
 			slog.Debug("will use home trash, will use rename(2) to move", "trashDir", homeDir.Dir)
 		}
-		if err := trashFile(*homeDir, path, &deleteTime, opts.homeFallback || env.ONLY_HOME_TRASH); err != nil {
+		if trashInfoPath, err = trashFile(*homeDir, path, &deleteTime, record.ID, opts.homeFallback || env.ONLY_HOME_TRASH); err != nil {
 			glog.Errorf("cannot trash %q: %s\n", arg, err)
 			continue
 		}
 		usedDir = *homeDir
 
 	SUCCESS:
+		record.Files = append(record.Files, history.Entry{
+			OriginalPath:  path,
+			TrashInfoPath: trashInfoPath,
+		})
+
+		f := newPutFile(path, st)
+		f.TrashInfoPath = trashInfoPath
+		f.TrashPath = filepath.Join(usedDir.FilesDir(), strings.TrimSuffix(filepath.Base(trashInfoPath), ".trashinfo"))
+		f.TrashDir = usedDir.Dir
+		f.DeletedAt = deleteTime
+		trashed = append(trashed, f)
+
 		if opts.verbose {
 			fmt.Printf("trashed %q to %s\n", arg, posix.AbsPathToTilde(usedDir.Dir))
 		}
 	}
+
+	if len(record.Files) > 0 {
+		record.Time = deleteTime
+		if err := history.Append(record); err != nil {
+			slog.Warn("failed to save history, 'undo' is not available for this operation", "path", history.Path(), "error", err)
+		}
+	}
+
+	runPostHook(hook.PostPut, trashed)
+
+	removeIgnored(ignored, opts)
+
 	return nil
 }
 
-func trashFile(trashDir xdg.TrashDir, path string, deleteTime *time.Time, fallbackCopy bool) error {
+type ignoredFile struct {
+	arg    string
+	path   string // absolute
+	source string // matched rule
+}
+
+// Remove files matching ignore rules PERMANENTLY
+// They are left as is if not confirmed, and outside of a terminal unless --ignore-delete is given.
+func removeIgnored(files []ignoredFile, opts putOptions) {
+	if len(files) == 0 {
+		return
+	}
+
+	if opts.dryRun {
+		for _, f := range files {
+			fmt.Printf("would remove %q PERMANENTLY (ignored by %s)\n", f.arg, posix.AbsPathToTilde(f.source))
+		}
+		return
+	}
+
+	if !opts.ignoreDelete && !canPrompt {
+		for _, f := range files {
+			glog.Errorf("cannot remove %q PERMANENTLY without confirmation (ignored by %s), use --ignore-delete or --no-ignore\n", f.arg, posix.AbsPathToTilde(f.source))
+		}
+		return
+	}
+
+	if !opts.ignoreDelete {
+		for _, f := range files {
+			fmt.Printf("%s\t(ignored by %s)\n", f.arg, posix.AbsPathToTilde(f.source))
+		}
+
+		fmt.Println("")
+		if !tui.BoolPrompt(fmt.Sprintf("Do you remove above %d ignored items PERMANENTLY? ", len(files))) {
+			fmt.Println("Left as is, use --no-ignore to trash them")
+			return
+		}
+	}
+
+	for _, f := range files {
+		slog.Debug("removing ignored file PERMANENTLY", "path", f.path, "source", f.source)
+		if err := os.RemoveAll(f.path); err != nil {
+			glog.Errorf("cannot remove %q: %s\n", f.arg, err)
+			continue
+		}
+
+		if opts.verbose {
+			fmt.Printf("removed %q PERMANENTLY (ignored by %s)\n", f.arg, posix.AbsPathToTilde(f.source))
+		}
+	}
+}
+
+// File passed to put hooks, trash paths are set after trashing
+func newPutFile(path string, st fs.FileInfo) trash.File {
+	f := trash.File{
+		Name:         filepath.Base(path),
+		OriginalPath: path,
+		IsDir:        st.IsDir(),
+		Mode:         st.Mode(),
+	}
+	if st.Mode().IsRegular() {
+		size := st.Size()
+		f.Size = &size
+	}
+	return f
+}
+
+// Move path to trashDir, and returns the path of the saved .trashinfo
+func trashFile(trashDir xdg.TrashDir, path string, deleteTime *time.Time, session string, fallbackCopy bool) (trashInfoPath string, err error) {
 	if err := trashDir.CreateDir(); err != nil {
-		return fmt.Errorf("create trash directory: %w\n", err)
+		return "", fmt.Errorf("create trash directory: %w\n", err)
 	}
 
 	infoPath := path
@@ -291,15 +494,19 @@ func trashFile(trashDir xdg.TrashDir, path string, deleteTime *time.Time, fallba
 		Path:         infoPath,
 		DeletionDate: *deleteTime,
 	}
+	if session != "" {
+		info.Extra = []xdg.InfoEntry{{Key: xdg.KeySession, Value: session}}
+	}
 
 	filename := filepath.Base(path)
 	// before rename(2), write .trashinfo metadata atomically
 	saveName, deleteFn, err := info.Save(trashDir, filename)
 	if err != nil {
-		return fmt.Errorf("save trashinfo: %w\n", err)
+		return "", fmt.Errorf("save trashinfo: %w\n", err)
 	}
 
-	slog.Debug("saved .trashinfo metadata", "path", filepath.Join(trashDir.InfoDir(), saveName+".trashinfo"))
+	trashInfoPath = filepath.Join(trashDir.InfoDir(), saveName+".trashinfo")
+	slog.Debug("saved .trashinfo metadata", "path", trashInfoPath)
 
 	// move file to trash
 	dstPath := filepath.Join(trashDir.FilesDir(), saveName)
@@ -312,23 +519,23 @@ func trashFile(trashDir xdg.TrashDir, path string, deleteTime *time.Time, fallba
 			// copy recursively
 			if err := cp.Copy(path, dstPath); err != nil {
 				_ = deleteFn()
-				return fmt.Errorf("fallback copy: %w", err)
+				return "", fmt.Errorf("fallback copy: %w", err)
 			}
 
 			// if copy success, then remove recursively
 			if err = os.RemoveAll(path); err != nil {
 				_ = deleteFn()
-				return fmt.Errorf("delete after fallback copy: %w", err)
+				return "", fmt.Errorf("delete after fallback copy: %w", err)
 			}
 
-			return nil
+			return trashInfoPath, nil
 		}
 
 		// delete corresponding .trashinfo file
 		_ = deleteFn()
 
-		return fmt.Errorf("move: %w", err)
+		return "", fmt.Errorf("move: %w", err)
 	}
 
-	return nil
+	return trashInfoPath, nil
 }
@@ -6,11 +6,11 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
-	cp "github.com/otiai10/copy"
-	"github.com/rs/xid"
 	"github.com/spf13/cobra"
 	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/hook"
 	"github.com/umlx5h/gtrash/internal/trash"
 	"github.com/umlx5h/gtrash/internal/tui"
 )
@@ -23,8 +23,19 @@ type restoreCmd struct {
 type restoreOptions struct {
 	directory string
 	cwd       bool
+	since     trash.TimeFlag
+	until     trash.UntilFlag
+	types     []string
+	exts      []string
+	owner     string
+	where     string
 	restoreTo string
+	conflict  conflictOptions
 	force     bool
+	dryRun    bool
+
+	fromFile string
+	null     bool
 }
 
 func newRestoreCmd() *restoreCmd {
@@ -38,19 +49,37 @@ func newRestoreCmd() *restoreCmd {
   Use the TUI interface to restore files, enabling multiple file selection.
   Press the ? key within the TUI interface for usage help.
 
-  When specifying the full path in the command-line argument, restoration is performed without using the TUI interface.`,
+  When specifying the full path in the command-line argument, restoration is performed without using the TUI interface.
+  A path inside a trashed directory can also be specified to restore only that file or directory.
+  The rest of the directory is left in the trash can.`,
 		Example: `  # Restore interactively
   $ gtrash restore
 
+  # Restore interactively from files deleted in the last hour
+  $ gtrash restore --since 1h
+
   # Restore files without TUI
   # Must specify full paths
   $ gtrash restore /home/user/file1 /home/user/file2
 
+  # Restore only a file inside the trashed directory /home/user/project
+  $ gtrash restore /home/user/project/src/main.go
+
   # Fuzzy find multiple items and restore them
   # The -o in xargs is necessary for the confirmation prompt to display.
-  $ gtrash find | fzf --multi | awk -F'\t' '{print $2}' | xargs -o gtrash restore`,
+  $ gtrash find | fzf --multi | awk -F'\t' '{print $2}' | xargs -o gtrash restore
+
+  # Same as above, but safe for paths containing tabs or newlines
+  $ gtrash find --print0 | fzf --multi --read0 --print0 | gtrash restore -0`,
 		SilenceUsage: true,
 		RunE: func(_ *cobra.Command, args []string) error {
+			args, err := argsWithPathList(args, root.opts.fromFile, root.opts.null)
+			if err != nil {
+				return err
+			}
+			if (root.opts.fromFile != "" || root.opts.null) && len(args) == 0 {
+				return errors.New("no paths are read from the list")
+			}
 			if err := restoreCmdRun(args, root.opts); err != nil {
 				return err
 			}
@@ -64,9 +93,18 @@ func newRestoreCmd() *restoreCmd {
 
 	cmd.Flags().StringVarP(&root.opts.directory, "directory", "d", "", "Filter by directory")
 	cmd.Flags().BoolVarP(&root.opts.cwd, "cwd", "c", false, "Filter by current working directory")
+	cmd.Flags().Var(&root.opts.since, "since", sinceFlagUsage)
+	cmd.Flags().Var(&root.opts.until, "until", untilFlagUsage)
+	addAttrFilterFlags(cmd, &root.opts.types, &root.opts.exts, &root.opts.owner)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
 	cmd.Flags().StringVar(&root.opts.restoreTo, "restore-to", "", "Restore to this path instead of original path")
+	addConflictFlags(cmd, &root.opts.conflict)
 	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always execute without confirmation prompt
 This is not necessary if running outside of a terminal`)
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage)
+	cmd.Flags().StringVar(&root.opts.fromFile, "from-file", "", fromFileFlagUsage)
+	cmd.Flags().BoolVarP(&root.opts.null, "null", "0", false, nullFlagUsage)
+	noConfig(cmd.Flags(), "from-file", "null", "force")
 
 	root.cmd = cmd
 	return root
@@ -82,8 +120,15 @@ func restoreCmdRun(args []string, opts restoreOptions) (err error) {
 	box := trash.NewBox(
 		trash.WithDirectory(opts.directory),
 		trash.WithCWD(opts.cwd),
+		trash.WithTimeRange(opts.since.Time, opts.until.Time),
+		trash.WithTypes(opts.types),
+		trash.WithExts(opts.exts),
+		trash.WithOwner(opts.owner),
+		trash.WithWhere(opts.where),
 		trash.WithQueries(args),               // only used when specifying command args
 		trash.WithQueryMode(trash.ModeByFull), // only support full match
+		trash.WithSubPath(true),
+		trash.WithGetMode(hook.Installed(hook.PostRestore)), // files are passed after restoring
 	)
 	if err := box.Open(); err != nil {
 		return err
@@ -95,7 +140,11 @@ func restoreCmdRun(args []string, opts restoreOptions) (err error) {
 		}
 
 		// interactive restore when not specifying command line args
-		box.Files, err = tui.FilesSelect(box.Files)
+		var removed []trash.File
+		release := holdHookOutput()
+		box.Files, removed, err = tui.FilesSelect(box.Files, removeFile)
+		release()
+		printRemoved(removed)
 		if err != nil {
 			return err
 		}
@@ -115,17 +164,26 @@ func restoreCmdRun(args []string, opts restoreOptions) (err error) {
 		fmt.Printf("Will restore to %q instead of original path\n", opts.restoreTo)
 	}
 
-	if !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
+	if !opts.dryRun && !opts.force && canPrompt && !tui.BoolPrompt("Are you sure you want to restore? ") {
 		return errors.New("do nothing")
 	}
 
-	if err := doRestore(box.Files, opts.restoreTo, isTerminal && !opts.force); err != nil {
+	if err := doRestore(box.Files, opts.restoreTo, opts.conflict, canPrompt && !opts.force, opts.dryRun); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Report files removed in the TUI, since the screen is cleared on exit
+func printRemoved(files []trash.File) {
+	if len(files) == 0 {
+		return
+	}
+	listFiles(files, false, false)
+	fmt.Printf("\nRemoved %d trashed files PERMANENTLY\n\n", len(files))
+}
+
 func checkOptRestoreTo(restoreTo *string) error {
 	if restoreTo == nil {
 		return nil
@@ -175,19 +233,61 @@ func checkRestoreDup(files []trash.File) error {
 	return nil
 }
 
-func doRestore(files []trash.File, restoreTo string, prompt bool) error {
-	if !prompt {
-		if err := checkRestoreDup(files); err != nil {
+// Detect files inside trashed directories which are restored together with the directory
+func checkRestoreSubPath(files []trash.File) error {
+	var conflicted bool
+	for _, sub := range files {
+		if sub.SubPath == "" {
+			continue
+		}
+		for _, f := range files {
+			if f.TrashInfoPath != sub.TrashInfoPath || f.SubPath == sub.SubPath {
+				continue
+			}
+			if f.SubPath == "" || strings.HasPrefix(sub.SubPath, f.SubPath+string(os.PathSeparator)) {
+				conflicted = true
+				glog.Errorf("conflict restore %q: %q is also restored\n", sub.OriginalPath, f.OriginalPath)
+				break
+			}
+		}
+	}
+
+	if conflicted {
+		return errors.New("canceled: restore conflict detected")
+	}
+
+	return nil
+}
+
+// If dryRun is true, conflicts are only reported without prompting
+func doRestore(files []trash.File, restoreTo string, conflict conflictOptions, prompt bool, dryRun bool) error {
+	if err := checkRestoreSubPath(files); err != nil {
+		return err
+	}
+
+	if (!prompt || dryRun) && conflict.policy == conflictAsk {
+		// continue to report other conflicts in dry-run
+		if err := checkRestoreDup(files); err != nil && !dryRun {
 			return err
 		}
 	}
 
 	var (
-		success int
-		failed  []trash.File
+		success  int
+		failed   []trash.File
+		restored []trash.File // passed to post-restore hook
 	)
 
 	printResult := func() {
+		if dryRun {
+			fmt.Printf("Would restore %d/%d trashed files (dry-run)\n", success, len(files))
+			if len(failed) > 0 {
+				fmt.Printf("Following %d files would not be restored.\n", len(failed))
+				listFiles(failed, false, true)
+			}
+			return
+		}
+
 		if restoreTo != "" {
 			fmt.Printf("Restored to %q\n", restoreTo)
 		}
@@ -199,6 +299,11 @@ func doRestore(files []trash.File, restoreTo string, prompt bool) error {
 		}
 	}
 
+	// after printing the result
+	defer func() {
+		runPostHook(hook.PostRestore, restored)
+	}()
+
 	defer printResult()
 
 	var (
@@ -214,43 +319,117 @@ func doRestore(files []trash.File, restoreTo string, prompt bool) error {
 			restorePath = filepath.Join(restoreTo, file.OriginalPath)
 		}
 
+		var overwrite, merge bool
+
 		// Check to see if the file already exists in the destination path.
 		// This is necessary because rename(2) overwrites the file.
 		if _, err := os.Lstat(restorePath); err == nil {
-			if !prompt {
-				glog.Errorf("cannot restore %q: restore path already exists\n", file.OriginalPath)
-				failed = append(failed, file)
-				continue
-			}
-			if !repeat {
-				choice := []string{"new-name", "skip", "quit"}
-				if prevSelect != "" {
-					choice = []string{"new-name", "skip", "repeat-prev", "quit"}
+			policy := conflict.policy
+
+			if policy == conflictAsk {
+				if !prompt || dryRun {
+					glog.Errorf("cannot restore %q: restore path already exists\n", file.OriginalPath)
+					failed = append(failed, file)
+					continue
 				}
-				// TODO: Make the message easy to understand
-				selected, err = tui.ChoicePrompt(fmt.Sprintf("Conflicted restore path %q\n\tPlease choose one of the following: ", file.OriginalPath), choice)
-				if err != nil {
-					return err
+				if !repeat {
+					// choices are selected by the first letter
+					choice := []string{"new-name", "overwrite"}
+					if bothDir(file.TrashPath, restorePath) {
+						choice = append(choice, "merge")
+					}
+					choice = append(choice, "keep-newer", "skip", "diff")
+					if prevSelect != "" {
+						choice = append(choice, "repeat-prev")
+					}
+					choice = append(choice, "quit")
+					for {
+						// TODO: Make the message easy to understand
+						selected, err = tui.ChoicePrompt(fmt.Sprintf("Conflicted restore path %q\n\tPlease choose one of the following: ", file.OriginalPath), choice)
+						if err != nil {
+							return err
+						}
+						if selected != "diff" {
+							break
+						}
+						// show the difference and ask again
+						if err := printConflictDiff(os.Stdout, file.TrashPath, restorePath); err != nil {
+							fmt.Printf("cannot show diff: %s\n", err)
+						}
+						fmt.Println()
+					}
 				}
+
+			SWITCH:
+				switch selected {
+				case "new-name":
+					policy = conflictRename
+				case "overwrite":
+					policy = conflictOverwrite
+				case "merge":
+					policy = conflictMerge
+				case "keep-newer":
+					policy = conflictNewer
+				case "skip":
+					policy = conflictSkip
+				case "repeat-prev":
+					repeat = true
+					selected = prevSelect
+					goto SWITCH
+				}
+				prevSelect = selected
 			}
 
-		SWITCH:
-			switch selected {
-			case "new-name":
-				// give a random string to avoid duplicates
-				restorePath = restorePath + "." + xid.New().String()
+			if policy == conflictMerge && !bothDir(file.TrashPath, restorePath) {
+				policy = conflictType(conflict.mergeRule)
+			}
+
+			switch policy {
+			case conflictRename:
+				restorePath = numberedPath(restorePath, file.IsDir)
 				fmt.Printf("Restoring to %q (original: %q)\n", restorePath, file.Name)
-				prevSelect = selected
-			case "skip":
-				prevSelect = selected
+			case conflictOverwrite:
+				overwrite = true
+			case conflictMerge:
+				merge = true
+			case conflictNewer:
+				newer, err := isNewer(file.TrashPath, restorePath)
+				if err != nil {
+					glog.Errorf("cannot restore %q: compare mtime: %s\n", file.OriginalPath, err)
+					failed = append(failed, file)
+					continue
+				}
+				if !newer {
+					fmt.Printf("Skipped %q: restore path is newer\n", file.OriginalPath)
+					continue
+				}
+				overwrite = true
+			default: // skip
+				fmt.Printf("Skipped %q: restore path already exists\n", file.OriginalPath)
 				continue
-			case "repeat-prev":
-				repeat = true
-				selected = prevSelect
-				goto SWITCH
 			}
 		}
 
+		if dryRun {
+			switch {
+			case overwrite:
+				fmt.Printf("would trash %q and restore %q to it\n", restorePath, file.TrashPath)
+			case merge:
+				fmt.Printf("would merge %q into %q\n", file.TrashPath, restorePath)
+			default:
+				fmt.Printf("would restore %q to %q\n", file.TrashPath, restorePath)
+			}
+			success++
+			continue
+		}
+
+		// after the conflict is resolved, not to report skipped files
+		if err := runPreHook(hook.PreRestore, file); err != nil {
+			glog.Errorf("cannot restore %q: %s\n", file.OriginalPath, err)
+			failed = append(failed, file)
+			continue
+		}
+
 		// ensure to have directory to restore
 		if err := os.MkdirAll(filepath.Dir(restorePath), 0o777); err != nil {
 			glog.Errorf("cannot restore %q: mkdir restorePath: %s\n", file.OriginalPath, err)
@@ -258,29 +437,43 @@ func doRestore(files []trash.File, restoreTo string, prompt bool) error {
 			continue
 		}
 
-		// "overwrite" is not an option because it only works when the source and destination files are both files.
+		// rename(2) cannot be used to overwrite because it only works when the source and destination files are both files.
 		// old     new
 		// file   file      old overwrites new
 		//  dir   file      error: not a directory
 		// file    dir      error: file exists
 		//  dir    dir      error: file exists
-
-		slog.Debug("executing rename(2) to restore", "from", file.TrashPath, "to", restorePath)
-		if err := os.Rename(file.TrashPath, restorePath); err != nil {
-			// rename(2) failed, fallback to copy and delete
-			slog.Debug("executing copy and delete to restore because rename(2) failed", "from", file.TrashPath, "to", restorePath)
-
-			// copy recursively
-			if err := cp.Copy(file.TrashPath, restorePath); err != nil {
-				glog.Errorf("cannot restore %q: fallback copy: %s\n", file.OriginalPath)
+		if overwrite {
+			if err := trashExisting(restorePath); err != nil {
+				glog.Errorf("cannot restore %q: trash the existing file: %s\n", file.OriginalPath, err)
 				failed = append(failed, file)
 				continue
 			}
+		}
 
-			// if copy success, then remove recursively
-			if err = os.RemoveAll(file.TrashPath); err != nil {
-				slog.Warn("restored successfully but cannot delete trashed file", "trashPath", file.TrashPath, "restoreTo", file.OriginalPath, "error", err)
+		if merge {
+			left, err := mergeDir(file.TrashPath, restorePath, conflictType(conflict.mergeRule))
+			if err != nil || left > 0 {
+				// files are partially restored, the rest is still in the trash can
+				if err := file.Touch(); err != nil {
+					slog.Warn("cannot update mtime of .trashinfo", "trashInfoPath", file.TrashInfoPath, "error", err)
+				}
+			}
+			if err != nil {
+				glog.Errorf("cannot restore %q: merge: %s\n", file.OriginalPath, err)
+				failed = append(failed, file)
+				continue
 			}
+			if left > 0 {
+				fmt.Printf("Merged into %q, %d conflicting files are left in the trash can\n", restorePath, left)
+				success++
+				restored = append(restored, file)
+				continue
+			}
+		} else if err := moveFile(file.TrashPath, restorePath); err != nil {
+			glog.Errorf("cannot restore %q: %s\n", file.OriginalPath, err)
+			failed = append(failed, file)
+			continue
 		}
 
 		if err := file.Delete(); err != nil {
@@ -288,6 +481,7 @@ func doRestore(files []trash.File, restoreTo string, prompt bool) error {
 		}
 
 		success++
+		restored = append(restored, file)
 	}
 
 	return nil
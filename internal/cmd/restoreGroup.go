@@ -15,7 +15,11 @@ type restoreGroupCmd struct {
 	opts restoreGroupOptions
 }
 
-type restoreGroupOptions struct{}
+type restoreGroupOptions struct {
+	dryRun   bool
+	where    string
+	conflict conflictOptions
+}
 
 func newRestoreGroupCmd() *restoreGroupCmd {
 	root := &restoreGroupCmd{}
@@ -30,8 +34,11 @@ func newRestoreGroupCmd() *restoreGroupCmd {
 
   Multiple selections of groups are not allowed.
 
-  Actually, files deleted using 'gtrash put' may not be grouped accurately.
-  Files with deletion times matching in seconds are grouped together.
+  Files deleted by one 'gtrash put' command are grouped exactly,
+  because the command records a session ID (X-GTrash-Session) in .trashinfo.
+
+  Files trashed by other applications (or older versions of gtrash) have no session ID.
+  They are grouped by deletion times matching in seconds, which may not be accurate.
 
   Refer below for detailed information.
   ref: https://github.com/umlx5h/gtrash#how-does-the-restore-group-subcommand-work
@@ -50,12 +57,18 @@ func newRestoreGroupCmd() *restoreGroupCmd {
 		},
 	}
 
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
+	addConflictFlags(cmd, &root.opts.conflict)
+
 	root.cmd = cmd
 	return root
 }
 
-func restoreGroupCmdRun(_ restoreGroupOptions) error {
-	box := trash.NewBox()
+func restoreGroupCmdRun(opts restoreGroupOptions) error {
+	box := trash.NewBox(
+		trash.WithWhere(opts.where),
+	)
 	if err := box.Open(); err != nil {
 		return err
 	}
@@ -70,11 +83,11 @@ func restoreGroupCmdRun(_ restoreGroupOptions) error {
 	listFiles(group.Files, false, false)
 	fmt.Printf("\nSelected %d trashed files\n", len(group.Files))
 
-	if isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
+	if !opts.dryRun && isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
 		return errors.New("do nothing")
 	}
 
-	if err := doRestore(group.Files, "", true); err != nil {
+	if err := doRestore(group.Files, "", opts.conflict, true, opts.dryRun); err != nil {
 		return err
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/hook"
 	"github.com/umlx5h/gtrash/internal/trash"
 	"github.com/umlx5h/gtrash/internal/tui"
 )
@@ -18,13 +19,22 @@ type removeCmd struct {
 }
 
 type removeOptions struct {
-	force bool
+	force  bool
+	dryRun bool
+
+	types []string
+	exts  []string
+	owner string
+	where string
+
+	fromFile string
+	null     bool
 }
 
 func newRemoveCmd() *removeCmd {
 	root := &removeCmd{}
 	cmd := &cobra.Command{
-		Use:   "rm PATH...",
+		Use:   "rm [PATH...]",
 		Short: "Remove trashed files PERMANENTLY in the cmd arguments",
 		Long: `Descricption:
   Permanently remove the files specified as command-line arguments.
@@ -35,12 +45,24 @@ func newRemoveCmd() *removeCmd {
 		Example: `  # Permanently remove files by providing full paths..
   $ gtrash rm /home/user/file1 /home/user/file2
 
+  # Permanently remove only directories among the given paths
+  $ gtrash rm --type d /home/user/dir1 /home/user/file1
+
   # Fuzzy find multiple items and permanently remove them.
   # The -o in xargs is necessary for the confirmation prompt to display.
-  $ gtrash find | fzf --multi | awk -F'\t' '{print $2}' | xargs -o gtrash rm`,
+  $ gtrash find | fzf --multi | awk -F'\t' '{print $2}' | xargs -o gtrash rm
+
+  # Same as above, but safe for paths containing tabs or newlines
+  $ gtrash find --print0 | fzf --multi --read0 --print0 | gtrash rm -0`,
 		SilenceUsage: true,
-		Args:         cobra.MinimumNArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
+			args, err := argsWithPathList(args, root.opts.fromFile, root.opts.null)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return errors.New("requires at least 1 path")
+			}
 			if err := removeCmdRun(args, root.opts); err != nil {
 				return err
 			}
@@ -53,6 +75,12 @@ func newRemoveCmd() *removeCmd {
 
 	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always execute without confirmation prompt
 This is not necessary if running outside of a terminal`)
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage)
+	addAttrFilterFlags(cmd, &root.opts.types, &root.opts.exts, &root.opts.owner)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
+	cmd.Flags().StringVar(&root.opts.fromFile, "from-file", "", fromFileFlagUsage)
+	cmd.Flags().BoolVarP(&root.opts.null, "null", "0", false, nullFlagUsage)
+	noConfig(cmd.Flags(), "from-file", "null", "force")
 
 	root.cmd = cmd
 	return root
@@ -63,6 +91,10 @@ func removeCmdRun(args []string, opts removeOptions) error {
 		trash.WithAscend(true),
 		trash.WithQueries(args),
 		trash.WithQueryMode(trash.ModeByFull),
+		trash.WithTypes(opts.types),
+		trash.WithExts(opts.exts),
+		trash.WithOwner(opts.owner),
+		trash.WithWhere(opts.where),
 	)
 	if err := box.Open(); err != nil {
 		return err
@@ -77,36 +109,64 @@ func removeCmdRun(args []string, opts removeOptions) error {
 	}
 	fmt.Printf("\nFound %d trashed files\n", len(box.Files))
 
-	if !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
+	if !opts.dryRun && !opts.force && canPrompt && !tui.BoolPrompt("Are you sure you want to remove PERMANENTLY? ") {
 		return errors.New("do nothing")
 	}
 
-	doRemove(box.Files)
+	doRemove(box.Files, opts.dryRun)
 
 	return nil
 }
 
-func doRemove(files []trash.File) {
-	var failed []trash.File
-
-	for _, file := range files {
-		slog.Debug("removing a trashed file", "path", file.TrashPath)
-		if err := os.RemoveAll(file.TrashPath); err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				glog.Errorf("cannot trash %q: remove: %s\n", file.TrashPath, err)
-				failed = append(failed, file)
-				continue
-			}
-		}
-		if err := file.Delete(); err != nil {
-			// already read, so it is usually not reached
-			slog.Warn("removed trashed file but cannot delete .trashinfo", "deletedFile", file.TrashPath, "trashInfoPath", file.TrashInfoPath, "error", err)
-		}
+// Returns the removed files
+func doRemove(files []trash.File, dryRun bool) []trash.File {
+	if dryRun {
+		fmt.Printf("Would remove %d trashed files (dry-run)\n", len(files))
+		return nil
 	}
 
+	removed, failed := removeFiles(files)
+
 	fmt.Printf("Removed %d/%d trashed files\n", len(files)-len(failed), len(files))
 	if len(failed) > 0 {
 		fmt.Printf("Following %d files could not be deleted.\n", len(failed))
 		listFiles(failed, false, true)
 	}
+
+	return removed
+}
+
+// Remove trashed files and their .trashinfo, then returns files removed and files that could not be removed
+func removeFiles(files []trash.File) (removed []trash.File, failed []trash.File) {
+	for _, file := range files {
+		if err := removeFile(file); err != nil {
+			glog.Errorf("cannot trash %q: remove: %s\n", file.TrashPath, err)
+			failed = append(failed, file)
+			continue
+		}
+		removed = append(removed, file)
+	}
+
+	return removed, failed
+}
+
+// Remove a trashed file and its .trashinfo
+// Also used by the TUI, so errors are returned instead of printed.
+func removeFile(file trash.File) error {
+	if err := runPreHook(hook.PreRemove, file); err != nil {
+		return err
+	}
+
+	slog.Debug("removing a trashed file", "path", file.TrashPath)
+	if err := os.RemoveAll(file.TrashPath); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := file.Delete(); err != nil {
+		// already read, so it is usually not reached
+		slog.Warn("removed trashed file but cannot delete .trashinfo", "deletedFile", file.TrashPath, "trashInfoPath", file.TrashInfoPath, "error", err)
+	}
+
+	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/lmittmann/tint"
 	"github.com/spf13/cobra"
 	"github.com/umlx5h/gtrash/internal/env"
+	"github.com/umlx5h/gtrash/internal/trash"
 	"golang.org/x/term"
 )
 
@@ -20,12 +21,15 @@ var (
 	errContinue = errors.New("")
 
 	isTerminal bool
+	// Whether confirmations can be asked, also true when stdin is the path list and /dev/tty is available
+	canPrompt bool
 )
 
 func init() {
 	if term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stdin.Fd())) {
 		isTerminal = true
 	}
+	canPrompt = isTerminal
 }
 
 func Execute(version Version) {
@@ -63,6 +67,43 @@ var (
 	isDebug bool
 )
 
+const dryRunFlagUsage = `Show what would be done without making any changes
+Confirmation prompts are skipped`
+
+const (
+	timeFlagUsage = `
+Duration before now (e.g. 90m, 2h, 3d, 2w, 1w2d),
+date or timestamp in local time (e.g. 2024-01-02, '2024-01-02 15:04'), today or yesterday`
+	sinceFlagUsage = "Filter by deletion date (deleted at or after this)" + timeFlagUsage
+	untilFlagUsage = "Filter by deletion date (deleted at or before this)" + timeFlagUsage + `
+A date without time includes the whole day (e.g. 2024-01-02 is until 2024-01-02 23:59:59)`
+)
+
+const (
+	typeFlagUsage = `Filter by file type, f: regular file, d: directory, l: symbolic link
+Can be specified multiple times or separated by comma (e.g. f,l)`
+	extFlagUsage = `Filter by extension of the original name, case insensitive (e.g. log, tar.gz)
+Can be specified multiple times or separated by comma`
+	ownerFlagUsage = "Filter by owner of the trashed file, user name or uid"
+)
+
+const whereFlagUsage = `Filter by expression, combined with other filters by AND
+Fields: name, path, trashpath, trashdir (=, !=, ~ glob, !~, =~ regex, !=~, contains),
+size (=, !=, <, <=, >, >= e.g. 1MB), date (same as size, e.g. 2d, 2024-01-02), dir, file, link
+Terms can be combined with and, or, not and parentheses
+e.g. 'name ~ "*.go" and size > 1MB and not dir'`
+
+// Register --type, --ext and --owner
+func addAttrFilterFlags(cmd *cobra.Command, types *[]string, exts *[]string, owner *string) {
+	cmd.Flags().StringSliceVar(types, "type", nil, typeFlagUsage)
+	cmd.Flags().StringSliceVar(exts, "ext", nil, extFlagUsage)
+	cmd.Flags().StringVar(owner, "owner", "", ownerFlagUsage)
+
+	if err := cmd.RegisterFlagCompletionFunc("type", trash.FlagCompletionFunc(trash.FileTypes)); err != nil {
+		panic(err)
+	}
+}
+
 type rootCmd struct {
 	cmd *cobra.Command
 }
@@ -75,6 +116,8 @@ func newRootCmd(version Version) *rootCmd {
 		}
 	}
 
+	var configErr error
+
 	root := &rootCmd{}
 	cmd := &cobra.Command{
 		Use:           progName,
@@ -83,7 +126,7 @@ func newRootCmd(version Version) *rootCmd {
 		Long: `Trash CLI manager written in Go
   https://github.com/umlx5h/gtrash`,
 		Version: version.Print(),
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			// setup debug log level
 			lvl := &slog.LevelVar{}
 
@@ -105,6 +148,17 @@ func newRootCmd(version Version) *rootCmd {
 				"HOME_TRASH_DIR", env.HOME_TRASH_DIR,
 				"ONLY_HOME_TRASH", env.ONLY_HOME_TRASH,
 			)
+
+			if err := errors.Join(env.ConfigErr, configErr); err != nil {
+				// still allow to investigate the configuration
+				if cmd.Parent() != nil && cmd.Parent().Name() == "config" {
+					slog.Warn("invalid configuration", "error", err)
+					return nil
+				}
+				return err
+			}
+
+			return checkExclusiveFlags(cmd)
 		},
 	}
 
@@ -127,11 +181,19 @@ func newRootCmd(version Version) *rootCmd {
 		newFindCmd().cmd,
 		newRestoreCmd().cmd,
 		newRestoreGroupCmd().cmd,
+		newTuiCmd().cmd,
+		newGrepCmd().cmd,
 		newRemoveCmd().cmd,
 		newSummaryCmd().cmd,
 		newMetafixCmd().cmd,
 		newPruneCmd().cmd,
+		newUndoCmd().cmd,
+		newConfigCmd().cmd,
 	)
+
+	// reported when running a subcommand
+	configErr = applyConfig(cmd, env.Config)
+
 	root.cmd = cmd
 	return root
 }
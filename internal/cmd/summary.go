@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
@@ -15,7 +16,10 @@ type summaryCmd struct {
 	opts summaryOptions
 }
 
-type summaryOptions struct{}
+type summaryOptions struct {
+	output outputType
+	where  string
+}
 
 func newSummaryCmd() *summaryCmd {
 	root := &summaryCmd{}
@@ -41,13 +45,21 @@ func newSummaryCmd() *summaryCmd {
 		},
 	}
 
+	cmd.Flags().VarP(&root.opts.output, "output", "o", outputFlagUsage)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
+
+	if err := cmd.RegisterFlagCompletionFunc("output", outputFlagCompletionFunc); err != nil {
+		panic(err)
+	}
+
 	root.cmd = cmd
 	return root
 }
 
-func summaryCmdRun(_ summaryOptions) error {
+func summaryCmdRun(opts summaryOptions) error {
 	box := trash.NewBox(
 		trash.WithGetSize(true),
+		trash.WithWhere(opts.where),
 	)
 
 	if err := box.Open(); err != nil {
@@ -59,6 +71,8 @@ func summaryCmdRun(_ summaryOptions) error {
 	var (
 		totalSize int64
 		totalItem int
+
+		summaries []trashDirSummaryJSON
 	)
 
 	for i, trashDir := range box.TrashDirs {
@@ -74,6 +88,18 @@ func summaryCmdRun(_ summaryOptions) error {
 			}
 		}
 
+		totalSize += size
+		totalItem += item
+
+		if opts.output.isJSON() {
+			summaries = append(summaries, trashDirSummaryJSON{
+				TrashDir: trashDir,
+				Items:    item,
+				Size:     size,
+			})
+			continue
+		}
+
 		fmt.Printf("[%s]\n", trashDir)
 		fmt.Printf("item: %d\n", item)
 		fmt.Printf("size: %s\n", humanize.Bytes(uint64(size)))
@@ -81,9 +107,27 @@ func summaryCmdRun(_ summaryOptions) error {
 		if i != len(box.TrashDirs)-1 {
 			fmt.Println("")
 		}
+	}
 
-		totalSize += size
-		totalItem += item
+	total := summaryTotalJSON{
+		Items: totalItem,
+		Size:  totalSize,
+	}
+
+	switch opts.output {
+	case outputNDJSON:
+		if err := writeJSON(os.Stdout, opts.output, summaries); err != nil {
+			return err
+		}
+		return writeJSON(os.Stdout, opts.output, []summaryTotalLineJSON{{Total: total}})
+	case outputJSON:
+		if summaries == nil {
+			summaries = []trashDirSummaryJSON{}
+		}
+		return writeJSONDocument(os.Stdout, summaryJSON{
+			TrashDirs: summaries,
+			Total:     total,
+		})
 	}
 
 	if len(box.TrashDirs) > 1 {
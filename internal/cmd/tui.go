@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/trash"
+	"github.com/umlx5h/gtrash/internal/tui"
+)
+
+type tuiCmd struct {
+	cmd  *cobra.Command
+	opts tuiOptions
+}
+
+type tuiOptions struct {
+	directory string
+	cwd       bool
+	since     trash.TimeFlag
+	until     trash.UntilFlag
+	types     []string
+	exts      []string
+	owner     string
+	where     string
+	restoreTo string
+	conflict  conflictOptions
+	force     bool
+}
+
+func newTuiCmd() *tuiCmd {
+	root := &tuiCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Restore or remove trashed files interactively",
+		Long: `Description:
+  Use the TUI interface to restore or remove trashed files.
+  Press the ? key within the TUI interface for usage help.
+
+  Same as 'restore' without arguments, but files can be removed PERMANENTLY as well.
+  Press x to mark files to remove, then press X to remove them after confirmation.
+  Files selected to restore are restored when Enter is pressed.`,
+		Example: `  # Manage all trashed files
+  $ gtrash tui
+
+  # Manage files deleted over a week ago under the current directory
+  $ gtrash tui --cwd --until 1w`,
+		SilenceUsage:      true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := tuiCmdRun(root.opts); err != nil {
+				return err
+			}
+			if glog.ExitCode() > 0 {
+				return errContinue
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&root.opts.directory, "directory", "d", "", "Filter by directory")
+	cmd.Flags().BoolVarP(&root.opts.cwd, "cwd", "c", false, "Filter by current working directory")
+	cmd.Flags().Var(&root.opts.since, "since", sinceFlagUsage)
+	cmd.Flags().Var(&root.opts.until, "until", untilFlagUsage)
+	addAttrFilterFlags(cmd, &root.opts.types, &root.opts.exts, &root.opts.owner)
+	cmd.Flags().StringVar(&root.opts.where, "where", "", whereFlagUsage)
+	cmd.Flags().StringVar(&root.opts.restoreTo, "restore-to", "", "Restore to this path instead of original path")
+	addConflictFlags(cmd, &root.opts.conflict)
+	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, "Restore without confirmation prompt after the TUI")
+
+	cmd.MarkFlagsMutuallyExclusive("directory", "cwd")
+	noConfig(cmd.Flags(), "force")
+
+	root.cmd = cmd
+	return root
+}
+
+func tuiCmdRun(opts tuiOptions) error {
+	if err := checkOptRestoreTo(&opts.restoreTo); err != nil {
+		return err
+	}
+
+	if !isTerminal {
+		return errors.New("cannot use tui interface outside of a terminal")
+	}
+
+	slog.Debug("starting tui")
+
+	box := trash.NewBox(
+		trash.WithDirectory(opts.directory),
+		trash.WithCWD(opts.cwd),
+		trash.WithTimeRange(opts.since.Time, opts.until.Time),
+		trash.WithTypes(opts.types),
+		trash.WithExts(opts.exts),
+		trash.WithOwner(opts.owner),
+		trash.WithWhere(opts.where),
+	)
+	if err := box.Open(); err != nil {
+		return err
+	}
+
+	release := holdHookOutput()
+	files, removed, err := tui.FilesSelect(box.Files, removeFile)
+	release()
+	printRemoved(removed)
+	if err != nil {
+		// only removed in the TUI
+		if errors.Is(err, tui.ErrNoSelected) && len(removed) > 0 {
+			return nil
+		}
+		return err
+	}
+
+	listFiles(files, false, false)
+	fmt.Printf("\nSelected %d trashed files\n", len(files))
+
+	if opts.restoreTo != "" {
+		fmt.Printf("Will restore to %q instead of original path\n", opts.restoreTo)
+	}
+
+	if !opts.force && !tui.BoolPrompt("Are you sure you want to restore? ") {
+		return errors.New("do nothing")
+	}
+
+	return doRestore(files, opts.restoreTo, opts.conflict, !opts.force, false)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/juju/ansiterm"
+	"github.com/spf13/cobra"
+	"github.com/umlx5h/gtrash/internal/glog"
+	"github.com/umlx5h/gtrash/internal/history"
+	"github.com/umlx5h/gtrash/internal/posix"
+	"github.com/umlx5h/gtrash/internal/trash"
+	"github.com/umlx5h/gtrash/internal/tui"
+)
+
+type undoCmd struct {
+	cmd  *cobra.Command
+	opts undoOptions
+}
+
+type undoOptions struct {
+	force  bool
+	list   bool
+	dryRun bool
+}
+
+func newUndoCmd() *undoCmd {
+	root := &undoCmd{}
+	cmd := &cobra.Command{
+		Use:   "undo [N]",
+		Short: "Restore files trashed by the last put",
+		Long: `Description:
+  Restore exactly the files trashed by the most recent 'put' command.
+  Specify N to undo the N-th most recent 'put' instead (1 is the most recent).
+
+  Each 'put' invocation is recorded in $XDG_DATA_HOME/gtrash/history.jsonl.
+  Undone invocations are removed from the history, so running 'undo' repeatedly goes back in order.
+
+  Files already restored or removed from the trash can are skipped.
+  Files trashed by other applications cannot be undone, use 'restore' instead.`,
+		Example: `  # Restore files trashed by the last put
+  $ gtrash undo
+
+  # Show the history of put
+  $ gtrash undo --list
+
+  # Restore files trashed by the put before last
+  $ gtrash undo 2`,
+		SilenceUsage:      true,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := undoCmdRun(args, root.opts); err != nil {
+				return err
+			}
+			if glog.ExitCode() > 0 {
+				return errContinue
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&root.opts.force, "force", "f", false, `Always execute without confirmation prompt
+This is not necessary if running outside of a terminal`)
+	cmd.Flags().BoolVar(&root.opts.list, "list", false, "Show the history of put, most recent first")
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, dryRunFlagUsage)
+
+	noConfig(cmd.Flags(), "list", "force")
+
+	root.cmd = cmd
+	return root
+}
+
+func undoCmdRun(args []string, opts undoOptions) error {
+	n := 1
+	if len(args) > 0 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("N must be a positive number: %q", args[0])
+		}
+	}
+
+	records, err := history.Load()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	if len(records) == 0 {
+		return errors.New("no put history to undo")
+	}
+
+	if opts.list {
+		listRecords(records)
+		return nil
+	}
+
+	if n > len(records) {
+		return fmt.Errorf("only %d put history exists", len(records))
+	}
+
+	record := records[len(records)-n]
+	slog.Debug("starting undo", "id", record.ID, "time", record.Time, "files", len(record.Files))
+
+	infoPaths := make(map[string]bool, len(record.Files))
+	queries := make([]string, len(record.Files))
+	for i, e := range record.Files {
+		infoPaths[e.TrashInfoPath] = true
+		queries[i] = e.OriginalPath
+	}
+
+	box := trash.NewBox(
+		trash.WithAscend(true),
+		trash.WithQueries(queries),
+		trash.WithQueryMode(trash.ModeByFull),
+	)
+	if err := box.Open(); err != nil && !errors.Is(err, trash.ErrNotFound) {
+		return err
+	}
+
+	// Files with the same original path may have been trashed by another invocation,
+	// so identify them by .trashinfo
+	var files []trash.File
+	found := make(map[string]bool, len(record.Files))
+	for _, f := range box.Files {
+		if infoPaths[f.TrashInfoPath] {
+			files = append(files, f)
+			found[f.TrashInfoPath] = true
+		}
+	}
+
+	for _, e := range record.Files {
+		if !found[e.TrashInfoPath] {
+			glog.Errorf("cannot undo %q: not found in trashcan\n", e.OriginalPath)
+		}
+	}
+
+	if len(files) == 0 {
+		// nothing left, no longer needed
+		if !opts.dryRun {
+			if err := history.Remove(record.ID); err != nil {
+				slog.Warn("failed to remove history", "path", history.Path(), "error", err)
+			}
+		}
+		return errors.New("do nothing: all files have already been restored or removed")
+	}
+
+	listFiles(files, false, false)
+	fmt.Printf("\nSelected %d trashed files\n", len(files))
+
+	if !opts.dryRun && !opts.force && isTerminal && !tui.BoolPrompt("Are you sure you want to restore? ") {
+		return errors.New("do nothing")
+	}
+
+	if err := doRestore(files, "", conflictOptions{}, isTerminal && !opts.force, opts.dryRun); err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+
+	// Keep the record if some files are still in the trash can (e.g. skipped by conflict)
+	for _, f := range files {
+		if _, err := os.Lstat(f.TrashInfoPath); err == nil {
+			return nil
+		}
+	}
+
+	if err := history.Remove(record.ID); err != nil {
+		slog.Warn("failed to remove history", "path", history.Path(), "error", err)
+	}
+
+	return nil
+}
+
+func listRecords(records []history.Record) {
+	green := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	header := func(s string) string {
+		if isTerminal {
+			return green.Render(s)
+		}
+		return s
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", header("N"), header("Date"), header("Files"), header("Path"))
+
+	// most recent first, same as the argument of undo
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+
+		paths := make([]string, 0, len(r.Files))
+		for _, e := range r.Files {
+			paths = append(paths, posix.AbsPathToTilde(e.OriginalPath))
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", len(records)-i, r.Time.Format(time.DateTime), len(r.Files), strings.Join(paths, " "))
+	}
+	w.Flush()
+}
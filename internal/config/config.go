@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gobwas/glob"
+)
+
+// Config file, environment variables and command-line options take precedence over this.
+// ref: doc/configuration.md
+type Config struct {
+	// same as $GTRASH_HOME_TRASH_DIR
+	HomeTrashDir string `toml:"home_trash_dir"`
+	// same as $GTRASH_ONLY_HOME_TRASH
+	OnlyHomeTrash bool `toml:"only_home_trash"`
+	// same as $GTRASH_HOME_TRASH_FALLBACK_COPY
+	HomeTrashFallbackCopy bool `toml:"home_trash_fallback_copy"`
+
+	// Mountpoints scanned for external trash cans
+	Mount Mount `toml:"mount"`
+
+	// Retention policies used by 'prune --policy'
+	Policies []Policy `toml:"policy"`
+
+	// Paths 'put' refuses to trash in addition to the built-in ones
+	Protect Protect `toml:"protect"`
+
+	// Default values of command-line options per subcommand.
+	// e.g. Commands["find"]["sort"] = "size" from
+	//   [find]
+	//   sort = "size"
+	Commands map[string]map[string]any `toml:"-"`
+}
+
+// $XDG_CONFIG_HOME/gtrash/config.toml ($HOME/.config/gtrash/config.toml)
+// Cannot use xdg package because xdg depends on this via env.
+func Path() string {
+	if d, ok := os.LookupEnv("XDG_CONFIG_HOME"); ok && d != "" {
+		if abs, err := filepath.Abs(d); err == nil {
+			return filepath.Join(abs, "gtrash", "config.toml")
+		}
+	}
+
+	return filepath.Join(homeDir(), ".config", "gtrash", "config.toml")
+}
+
+// Load the config file
+// If the file does not exist, returns an empty config without error.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return &Config{}, fmt.Errorf("config: %w", err)
+	}
+
+	c, err := parse(string(b))
+	if err != nil {
+		return &Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+func parse(data string) (*Config, error) {
+	var c Config
+	md, err := toml.Decode(data, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	// top-level tables not decoded into Config are options of subcommands
+	var raw map[string]any
+	if _, err := toml.Decode(data, &raw); err != nil {
+		return nil, err
+	}
+
+	c.Commands = make(map[string]map[string]any)
+	for _, key := range md.Undecoded() {
+		name := key[0]
+		table, ok := raw[name].(map[string]any)
+		if !ok || name == "mount" || name == "protect" {
+			return nil, fmt.Errorf("unknown key %q", key.String())
+		}
+		if len(key) > 2 {
+			return nil, fmt.Errorf("[%s] nested table is not supported: %q", name, key.String())
+		}
+		c.Commands[name] = table
+	}
+
+	c.HomeTrashDir = ExpandHome(c.HomeTrashDir)
+	for i := range c.Policies {
+		c.Policies[i].TrashDir = ExpandHome(c.Policies[i].TrashDir)
+		c.Policies[i].Path = ExpandHome(c.Policies[i].Path)
+	}
+
+	if err := c.Mount.check(); err != nil {
+		return nil, fmt.Errorf("[mount] %w", err)
+	}
+
+	if err := c.Protect.check(); err != nil {
+		return nil, fmt.Errorf("[protect] %w", err)
+	}
+
+	return &c, nil
+}
+
+// Default timeout of stat(2) for each mountpoint
+const DefaultMountTimeout = time.Second
+
+// Mountpoints scanned for external trash cans, defined by [mount]
+// A mountpoint is skipped if it matches Exclude*, unless it also matches Include*.
+// Include* also enables pseudo file systems skipped by default.
+type Mount struct {
+	Timeout string `toml:"timeout"` // e.g. "500ms", "0" disables timeout (default: 1s)
+
+	IncludeFSTypes []string `toml:"include_fstypes"`
+	ExcludeFSTypes []string `toml:"exclude_fstypes"`
+	IncludePaths   []string `toml:"include_paths"` // glob pattern of the mountpoint
+	ExcludePaths   []string `toml:"exclude_paths"` // glob pattern of the mountpoint
+}
+
+func (m *Mount) check() error {
+	if m.Timeout != "" {
+		d, err := time.ParseDuration(m.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout is invalid: %w", err)
+		}
+		if d < 0 {
+			return errors.New("timeout must not be negative")
+		}
+	}
+
+	for _, paths := range [][]string{m.IncludePaths, m.ExcludePaths} {
+		for i, p := range paths {
+			paths[i] = ExpandHome(p)
+			if _, err := glob.Compile(paths[i], '/'); err != nil {
+				return fmt.Errorf("%q is invalid glob: %w", p, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the timeout of stat(2) for each mountpoint, 0 is unlimited
+func (m Mount) StatTimeout() time.Duration {
+	if m.Timeout == "" {
+		return DefaultMountTimeout
+	}
+	d, err := time.ParseDuration(m.Timeout)
+	if err != nil {
+		// already checked when loaded
+		return DefaultMountTimeout
+	}
+	return d
+}
+
+// Paths 'put' refuses to trash without --i-know, defined by [protect]
+type Protect struct {
+	// glob pattern of the full path, matches the base name if not containing '/' (e.g. ".git")
+	Paths []string `toml:"paths"`
+}
+
+func (p *Protect) check() error {
+	for i, path := range p.Paths {
+		p.Paths[i] = ExpandHome(path)
+		if _, err := glob.Compile(p.Paths[i], '/'); err != nil {
+			return fmt.Errorf("%q is invalid glob: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Retention policy, defined by [[policy]]
+// Files are selected by TrashDir and Path, empty matches all.
+type Policy struct {
+	Name string `toml:"name"`
+
+	TrashDir string `toml:"trash_dir"` // full path of the trash can
+	Path     string `toml:"path"`      // glob pattern of the original path
+
+	Day  int    `toml:"day"`  // remove files deleted before X days
+	Size string `toml:"size"` // human size, remove larger files first until the total is less than this
+	Keep int    `toml:"keep"` // always keep X most recently deleted files
+}
+
+// Returns subcommand names in the config, sorted
+func (c *Config) CommandNames() []string {
+	names := make([]string, 0, len(c.Commands))
+	for n := range c.Commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand leading ~ to the home directory
+func ExpandHome(path string) string {
+	if path == "~" {
+		return homeDir()
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir(), path[2:])
+	}
+	return path
+}
+
+func homeDir() string {
+	if h := os.Getenv("HOME"); h != "" {
+		return h
+	}
+	if u, err := user.Current(); err == nil {
+		return u.HomeDir
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+
+	c, err := parse(`
+home_trash_dir = "~/.gtrash"
+only_home_trash = true
+
+[find]
+sort = "size"
+last = 10
+
+[put]
+rm-mode = true
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/home/user/.gtrash", c.HomeTrashDir)
+	assert.True(t, c.OnlyHomeTrash)
+	assert.False(t, c.HomeTrashFallbackCopy)
+	assert.Equal(t, []string{"find", "put"}, c.CommandNames())
+	assert.Equal(t, map[string]any{"sort": "size", "last": int64(10)}, c.Commands["find"])
+	assert.Equal(t, map[string]any{"rm-mode": true}, c.Commands["put"])
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+
+	c, err := parse(`
+[[policy]]
+name = "projects"
+path = "~/projects/**"
+day = 30
+
+[[policy]]
+trash_dir = "/mnt/data/.Trash-1000"
+size = "50GB"
+keep = 100
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Policy{
+		{Name: "projects", Path: "/home/user/projects/**", Day: 30},
+		{TrashDir: "/mnt/data/.Trash-1000", Size: "50GB", Keep: 100},
+	}, c.Policies)
+	assert.Empty(t, c.Commands)
+
+	_, err = parse("[[policy]]\nfoo = 1")
+	assert.Error(t, err)
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unknown key", data: `foo = 1`},
+		{name: "type mismatch", data: `only_home_trash = "yes"`},
+		{name: "nested table", data: "[find.foo]\nbar = 1"},
+		{name: "syntax", data: `[find`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadNotExist(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "config.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, c)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[prune]\nday = 30\n"), 0o600))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), c.Commands["prune"]["day"])
+}
+
+func TestParseMount(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+
+	c, err := parse(`
+[mount]
+timeout = "500ms"
+exclude_fstypes = ["nfs", "fuse.sshfs"]
+exclude_paths = ["~/mnt/**"]
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, 500*time.Millisecond, c.Mount.StatTimeout())
+	assert.Equal(t, []string{"nfs", "fuse.sshfs"}, c.Mount.ExcludeFSTypes)
+	assert.Equal(t, []string{"/home/user/mnt/**"}, c.Mount.ExcludePaths)
+	assert.Empty(t, c.Commands)
+
+	c, err = parse("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMountTimeout, c.Mount.StatTimeout())
+
+	for _, data := range []string{
+		"[mount]\ntimeout = \"1\"",
+		"[mount]\ntimeout = \"-1s\"",
+		"[mount]\nexclude_paths = [\"/mnt/[\"]",
+		"[mount]\nfoo = 1",
+	} {
+		_, err = parse(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestParseProtect(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+
+	c, err := parse(`
+[protect]
+paths = [".git", "~/.ssh", "/srv/**"]
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".git", "/home/user/.ssh", "/srv/**"}, c.Protect.Paths)
+	assert.Empty(t, c.Commands)
+
+	for _, data := range []string{
+		"[protect]\npaths = [\"/srv/[\"]",
+		"[protect]\nfoo = 1",
+	} {
+		_, err = parse(data)
+		assert.Error(t, err, data)
+	}
+}
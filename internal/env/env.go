@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/umlx5h/gtrash/internal/config"
 )
 
 var (
@@ -25,44 +27,63 @@ var (
 	// Whether to get as close to rm behavior as possible
 	// Default: false
 	PUT_RM_MODE bool
+
+	// Loaded from $XDG_CONFIG_HOME/gtrash/config.toml
+	// If failed to load, ConfigErr is set and Config is empty.
+	Config    *config.Config
+	ConfigErr error
 )
 
 func init() {
-	if e, ok := os.LookupEnv("GTRASH_HOME_TRASH_FALLBACK_COPY"); ok {
-		if strings.ToLower(strings.TrimSpace(e)) == "true" {
-			HOME_TRASH_FALLBACK_COPY = true
-		}
+	// environment variables take precedence over the config file
+	Config, ConfigErr = config.Load(config.Path())
+
+	HOME_TRASH_FALLBACK_COPY = Config.HomeTrashFallbackCopy
+	if v, ok := lookupBool("GTRASH_HOME_TRASH_FALLBACK_COPY"); ok {
+		HOME_TRASH_FALLBACK_COPY = v
 	}
 
-	if e, ok := os.LookupEnv("GTRASH_ONLY_HOME_TRASH"); ok {
-		if strings.ToLower(strings.TrimSpace(e)) == "true" {
-			ONLY_HOME_TRASH = true
-			// Also enable this
-			HOME_TRASH_FALLBACK_COPY = true
-		}
+	ONLY_HOME_TRASH = Config.OnlyHomeTrash
+	if v, ok := lookupBool("GTRASH_ONLY_HOME_TRASH"); ok {
+		ONLY_HOME_TRASH = v
+	}
+	if ONLY_HOME_TRASH {
+		// Also enable this
+		HOME_TRASH_FALLBACK_COPY = true
 	}
 
-	if e, ok := os.LookupEnv("GTRASH_PUT_RM_MODE"); ok {
-		if strings.ToLower(strings.TrimSpace(e)) == "true" {
-			PUT_RM_MODE = true
-		}
+	if v, ok := lookupBool("GTRASH_PUT_RM_MODE"); ok {
+		PUT_RM_MODE = v
 	}
 
-	if e, ok := os.LookupEnv("GTRASH_HOME_TRASH_DIR"); ok {
-		if e != "" {
-			path, err := filepath.Abs(e)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "ENV $GTRASH_HOME_TRASH_DIR is not valid path: %s", err)
-				os.Exit(1)
-			}
-
-			// Ensure to have directory in advance
-			if err := os.MkdirAll(path, 0o700); err != nil {
-				fmt.Fprintf(os.Stderr, "ENV $GTRASH_HOME_TRASH_DIR could not be created: %s", err)
-				os.Exit(1)
-			}
-
-			HOME_TRASH_DIR = path
+	homeTrashDir, source := Config.HomeTrashDir, "config home_trash_dir"
+	if e, ok := os.LookupEnv("GTRASH_HOME_TRASH_DIR"); ok && e != "" {
+		homeTrashDir, source = e, "ENV $GTRASH_HOME_TRASH_DIR"
+	}
+
+	if homeTrashDir != "" {
+		path, err := filepath.Abs(homeTrashDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s is not valid path: %s", source, err)
+			os.Exit(1)
+		}
+
+		// Ensure to have directory in advance
+		if err := os.MkdirAll(path, 0o700); err != nil {
+			fmt.Fprintf(os.Stderr, "%s could not be created: %s", source, err)
+			os.Exit(1)
 		}
+
+		HOME_TRASH_DIR = path
+	}
+}
+
+// Returns true only for 'true', false for others
+// ok is false if not set.
+func lookupBool(key string) (value bool, ok bool) {
+	e, ok := os.LookupEnv(key)
+	if !ok {
+		return false, false
 	}
+	return strings.ToLower(strings.TrimSpace(e)) == "true", true
 }
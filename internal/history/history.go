@@ -0,0 +1,185 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/umlx5h/gtrash/internal/xdg"
+)
+
+// Number of records kept, older records are dropped
+const maxRecords = 100
+
+// Files trashed by one 'put' invocation
+type Record struct {
+	ID    string    `json:"id"` // unique per invocation
+	Time  time.Time `json:"time"`
+	Files []Entry   `json:"files"`
+}
+
+type Entry struct {
+	OriginalPath  string `json:"original_path"`
+	TrashInfoPath string `json:"trash_info_path"`
+}
+
+// $XDG_DATA_HOME/gtrash/history.jsonl
+func Path() string {
+	return filepath.Join(xdg.DirAppData, "history.jsonl")
+}
+
+// Append a record to the history file
+// The file is rewritten if it has more than maxRecords records.
+func Append(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := Load()
+	if err != nil {
+		return err
+	}
+	if len(records) >= maxRecords {
+		return write(append(records, r))
+	}
+
+	f, err := os.OpenFile(Path(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Take an exclusive lock of the history file, so that records appended by concurrent 'put'
+// are not lost while 'undo' rewrites it. The lock is released by calling unlock.
+// A separate file is locked because the history file is replaced by rename(2).
+func lock() (unlock func(), err error) {
+	if err := os.MkdirAll(xdg.DirAppData, 0o700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(Path()+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("locking history", "path", f.Name())
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock history: %w", err)
+	}
+
+	// closing the file releases the lock
+	return func() { f.Close() }, nil
+}
+
+// Load all records, oldest first
+// Returns nil if the history file does not exist.
+func Load() ([]Record, error) {
+	f, err := os.Open(Path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return parse(f)
+}
+
+func parse(r io.Reader) ([]Record, error) {
+	s := bufio.NewScanner(r)
+	// a record of many files can be a long line
+	s.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var records []Record
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			// e.g. truncated by a crash, should not break other records
+			slog.Warn("skipped broken record in history", "path", Path(), "error", err)
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Remove the record with id from the history file
+func Remove(id string) error {
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := Load()
+	if err != nil {
+		return err
+	}
+
+	var keep []Record
+	for _, r := range records {
+		if r.ID != id {
+			keep = append(keep, r)
+		}
+	}
+
+	return write(keep)
+}
+
+// Rewrite the history file atomically with the last maxRecords records
+// Must be called with the lock held.
+func write(records []Record) error {
+	if len(records) > maxRecords {
+		records = records[len(records)-maxRecords:]
+	}
+
+	f, err := os.CreateTemp(xdg.DirAppData, "history_gtrash_")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+
+	return os.Rename(f.Name(), Path())
+}
@@ -0,0 +1,85 @@
+package history
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/xdg"
+)
+
+func TestParse(t *testing.T) {
+	records, err := parse(strings.NewReader(`{"id":"a","time":"2024-01-01T00:00:00Z","files":[{"original_path":"/foo","trash_info_path":"/trash/info/foo.trashinfo"}]}
+{"id":"broken",
+{"id":"b","time":"2024-01-02T00:00:00Z","files":[]}
+`))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].ID)
+	assert.Equal(t, []Entry{{OriginalPath: "/foo", TrashInfoPath: "/trash/info/foo.trashinfo"}}, records[0].Files)
+	assert.Equal(t, "b", records[1].ID)
+}
+
+func TestAppendRemove(t *testing.T) {
+	orig := xdg.DirAppData
+	xdg.DirAppData = filepath.Join(t.TempDir(), "gtrash")
+	t.Cleanup(func() { xdg.DirAppData = orig })
+
+	records, err := Load()
+	require.NoError(t, err)
+	assert.Nil(t, records, "should be nil when history does not exist")
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, Append(Record{ID: id, Time: time.Now()}))
+	}
+
+	require.NoError(t, Remove("b"))
+
+	records, err = Load()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].ID)
+	assert.Equal(t, "c", records[1].ID)
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	orig := xdg.DirAppData
+	xdg.DirAppData = filepath.Join(t.TempDir(), "gtrash")
+	t.Cleanup(func() { xdg.DirAppData = orig })
+
+	require.NoError(t, Append(Record{ID: "old", Time: time.Now()}))
+
+	// records appended while rewriting are not lost
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, Append(Record{ID: strconv.Itoa(i), Time: time.Now()}))
+		}(i)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Remove("old"))
+		}()
+	}
+	wg.Wait()
+
+	records, err := Load()
+	require.NoError(t, err)
+	assert.Len(t, records, 20)
+
+	// capped without undo
+	for i := 20; i < maxRecords+10; i++ {
+		require.NoError(t, Append(Record{ID: strconv.Itoa(i), Time: time.Now()}))
+	}
+	records, err = Load()
+	require.NoError(t, err)
+	require.Len(t, records, maxRecords)
+	assert.Equal(t, "20", records[10].ID)
+	assert.Equal(t, strconv.Itoa(maxRecords+9), records[maxRecords-1].ID)
+}
@@ -0,0 +1,92 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/umlx5h/gtrash/internal/config"
+)
+
+// Hooks are executables placed in Dir() named after the event.
+// Affected files are passed as a JSON array on stdin.
+// ref: doc/configuration.md
+type Name string
+
+const (
+	PrePut      Name = "pre-put"      // per file, non-zero exit skips the file
+	PostPut     Name = "post-put"     // once with all trashed files
+	PreRestore  Name = "pre-restore"  // per file, non-zero exit skips the file
+	PostRestore Name = "post-restore" // once with all restored files
+	PreRemove   Name = "pre-remove"   // per file, non-zero exit skips the file
+	PostPrune   Name = "post-prune"   // once with all pruned files
+)
+
+// Where output of hooks goes, stderr not to be mixed with the output of gtrash (e.g. --output json)
+// Replaced while the TUI is running not to corrupt the screen.
+var Output io.Writer = os.Stderr
+
+// $XDG_CONFIG_HOME/gtrash/hooks ($HOME/.config/gtrash/hooks)
+func Dir() string {
+	return filepath.Join(filepath.Dir(config.Path()), "hooks")
+}
+
+// Path of the hook, empty if not installed
+// Files without the executable bit are ignored same as git.
+func lookup(name Name) string {
+	path := filepath.Join(Dir(), string(name))
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("cannot check hook, ignored", "path", path, "error", err)
+		}
+		return ""
+	}
+
+	if !fi.Mode().IsRegular() || fi.Mode().Perm()&0o111 == 0 {
+		slog.Warn("hook is not an executable file, ignored", "path", path)
+		return ""
+	}
+
+	return path
+}
+
+// Whether the hook is installed
+func Installed(name Name) bool {
+	return lookup(name) != ""
+}
+
+// Run the hook with v encoded as JSON on stdin, returns nil if not installed
+// Output of the hook goes to Output.
+func Run(name Name, v any) error {
+	path := lookup(name)
+	if path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = Output
+	cmd.Stderr = Output
+	cmd.Env = append(os.Environ(), "GTRASH_HOOK="+string(name))
+
+	slog.Debug("running hook", "name", name, "path", path)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package hook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	require.NoError(t, os.MkdirAll(Dir(), 0o755))
+
+	out := filepath.Join(t.TempDir(), "stdin.json")
+	writeHook := func(name Name, script string, perm os.FileMode) {
+		require.NoError(t, os.WriteFile(filepath.Join(Dir(), string(name)), []byte(script), perm))
+	}
+
+	// not installed
+	require.NoError(t, Run(PrePut, nil))
+	assert.False(t, Installed(PrePut))
+
+	writeHook(PrePut, "#!/bin/sh\ncat > "+out+"\n[ \"$GTRASH_HOOK\" = pre-put ]\n", 0o755)
+	require.NoError(t, Run(PrePut, []map[string]string{{"original_path": "/foo"}}))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"original_path":"/foo"}]`, string(b))
+
+	writeHook(PreRemove, "#!/bin/sh\nexit 1\n", 0o755)
+	assert.ErrorContains(t, Run(PreRemove, nil), "pre-remove hook: exit status 1")
+
+	assert.True(t, Installed(PreRemove))
+
+	// ignored without the executable bit
+	writeHook(PreRestore, "#!/bin/sh\nexit 1\n", 0o644)
+	assert.NoError(t, Run(PreRestore, nil))
+	assert.False(t, Installed(PreRestore))
+}
@@ -0,0 +1,272 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gobwas/glob"
+	"github.com/umlx5h/gtrash/internal/config"
+	"github.com/umlx5h/gtrash/internal/xdg"
+)
+
+// Rules of files removed PERMANENTLY by 'put' instead of trashing.
+// The syntax is a subset of .gitignore:
+//
+//	# comment
+//	*.o            matches the base name if not containing '/'
+//	/build         relative to the directory of .gtrashignore
+//	target/        matches only directories
+//	!keep.o        re-includes a file
+//
+// Files inside a directory ignored by a rule ending with or containing '/' are also ignored.
+// ref: doc/configuration.md
+
+// Per-directory rule file, discovered upward from the trashed path up to $HOME or the mountpoint
+// Files not owned by the user or root, or writable by others are skipped.
+const FileName = ".gtrashignore"
+
+// $XDG_CONFIG_HOME/gtrash/ignore ($HOME/.config/gtrash/ignore)
+// Patterns containing '/' are matched against the absolute path.
+func GlobalPath() string {
+	return filepath.Join(filepath.Dir(config.Path()), "ignore")
+}
+
+type rule struct {
+	source   string // file:line
+	pattern  string
+	glob     glob.Glob
+	alt      glob.Glob // without leading **/ to match the top level, may be nil
+	negate   bool
+	dirOnly  bool
+	baseOnly bool   // match the base name
+	dir      string // directory of .gtrashignore, empty for the global file
+}
+
+type Matcher struct {
+	global []rule
+	dirs   map[string][]rule // rules by directory of .gtrashignore, cached
+	tops   map[string]bool   // whether the directory stops the discovery, cached
+	home   string
+}
+
+// Load the global rules, per-directory rules are loaded when matching
+// If the global file does not exist, it is just ignored.
+func New(globalPath string) (*Matcher, error) {
+	m := &Matcher{
+		dirs: make(map[string][]rule),
+		tops: make(map[string]bool),
+		home: config.ExpandHome("~"),
+	}
+
+	f, err := os.Open(globalPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("ignore: %w", err)
+	}
+	defer f.Close()
+
+	if m.global, err = parse(f, globalPath, ""); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func parse(r io.Reader, path string, dir string) ([]rule, error) {
+	var rules []rule
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r := rule{
+			source: fmt.Sprintf("%s:%d", path, n),
+			dir:    dir,
+		}
+
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		r.pattern = line
+
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		r.baseOnly = !strings.Contains(line, "/")
+		if !r.baseOnly {
+			if dir == "" {
+				line = config.ExpandHome(line)
+				if !filepath.IsAbs(line) {
+					// e.g. build/out matches /any/build/out
+					line = "**/" + line
+				}
+			} else {
+				line = strings.TrimPrefix(line, "/")
+			}
+		}
+
+		g, err := glob.Compile(line, '/')
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is invalid glob: %w", r.source, r.pattern, err)
+		}
+		r.glob = g
+		if rest, ok := strings.CutPrefix(line, "**/"); ok && dir != "" {
+			r.alt = glob.MustCompile(rest, '/')
+		}
+
+		rules = append(rules, r)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// Rules of .gtrashignore in dir, invalid files are skipped with a warning
+func (m *Matcher) dirRules(dir string) []rule {
+	if rules, ok := m.dirs[dir]; ok {
+		return rules
+	}
+
+	path := filepath.Join(dir, FileName)
+
+	var rules []rule
+	if f, err := os.Open(path); err == nil {
+		if fi, err := f.Stat(); err != nil || !trusted(fi) {
+			slog.Warn("ignore file not owned by you or root, or writable by others, skipped", "path", path)
+		} else if rules, err = parse(f, path, dir); err != nil {
+			slog.Warn("invalid ignore file, skipped", "error", err)
+		}
+		f.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("cannot read ignore file, skipped", "path", path, "error", err)
+	}
+
+	m.dirs[dir] = rules
+	return rules
+}
+
+// Otherwise anyone could make 'put' remove files PERMANENTLY
+// by placing .gtrashignore in a shared directory such as /tmp.
+func trusted(fi fs.FileInfo) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	if int(st.Uid) != os.Getuid() && st.Uid != 0 {
+		return false
+	}
+	return fi.Mode().Perm()&0o002 == 0
+}
+
+// Whether .gtrashignore is not looked up above dir
+func (m *Matcher) isTop(dir string) bool {
+	if top, ok := m.tops[dir]; ok {
+		return top
+	}
+
+	top := dir == m.home || dir == filepath.Dir(dir)
+	if !top {
+		mounted, err := xdg.IsMountpoint(dir)
+		if err != nil {
+			slog.Debug("cannot check mountpoint, stop looking up ignore files", "path", dir, "error", err)
+		}
+		top = mounted || err != nil
+	}
+
+	m.tops[dir] = top
+	return top
+}
+
+// Global rules, then .gtrashignore from $HOME or the mountpoint to dir
+// Later rules take precedence.
+func (m *Matcher) rules(dir string) []rule {
+	var dirs []string
+	for d := dir; ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if m.isTop(d) {
+			break
+		}
+	}
+
+	rules := append([]rule(nil), m.global...)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rules = append(rules, m.dirRules(dirs[i])...)
+	}
+	return rules
+}
+
+// Whether the absolute path is ignored, returns the source of the matched rule
+func (m *Matcher) Match(path string, isDir bool) (source string, ignored bool) {
+	rules := m.rules(filepath.Dir(path))
+
+	// files inside an ignored directory are ignored, cannot be re-included
+	var parents []string
+	for d := filepath.Dir(path); d != filepath.Dir(d); d = filepath.Dir(d) {
+		parents = append(parents, d)
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		if source, ok := match(rules, parents[i], true, true); ok {
+			return source, true
+		}
+	}
+
+	return match(rules, path, isDir, false)
+}
+
+// The last matched rule wins
+// For parent directories, only rules ending with '/' or containing '/' are used,
+// so that e.g. "build" does not remove everything under ~/build.
+func match(rules []rule, path string, isDir bool, parent bool) (source string, ignored bool) {
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if parent && r.baseOnly && !r.dirOnly {
+			continue
+		}
+
+		target := path
+		if r.dir != "" {
+			rel, err := filepath.Rel(r.dir, path)
+			if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+				// not under the directory of .gtrashignore
+				continue
+			}
+			target = rel
+		}
+		if r.baseOnly {
+			target = filepath.Base(path)
+		}
+
+		if r.glob.Match(target) || (r.alt != nil && r.alt.Match(target)) {
+			source, ignored = r.source, !r.negate
+		}
+	}
+
+	if !ignored {
+		return "", false
+	}
+	return source, true
+}
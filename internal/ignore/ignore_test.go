@@ -0,0 +1,100 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tmp := t.TempDir()
+	global := filepath.Join(tmp, "ignore")
+	require.NoError(t, os.WriteFile(global, []byte(`
+# build artefacts
+*.o
+node_modules/
+`+tmp+`/cache/**
+`), 0o644))
+
+	project := filepath.Join(tmp, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, FileName), []byte(`
+/build
+target/
+**/gen
+!keep.o
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "sub", FileName), []byte("*.tmp\n"), 0o644))
+
+	m, err := New(global)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+		source  string
+	}{
+		{filepath.Join(tmp, "a.o"), false, true, global + ":3"},
+		{filepath.Join(tmp, "a.c"), false, false, ""},
+		{filepath.Join(tmp, "dir.o", "a.c"), false, false, ""},
+		{filepath.Join(tmp, "node_modules"), true, true, global + ":4"},
+		{filepath.Join(tmp, "node_modules"), false, false, ""},
+		{filepath.Join(tmp, "node_modules", "pkg", "index.js"), false, true, global + ":4"},
+		{filepath.Join(tmp, "cache", "x"), false, true, global + ":5"},
+		{filepath.Join(project, "build"), true, true, filepath.Join(project, FileName) + ":2"},
+		{filepath.Join(project, "sub", "build"), true, false, ""},
+		{filepath.Join(project, "sub", "target"), true, true, filepath.Join(project, FileName) + ":3"},
+		{filepath.Join(project, "gen"), false, true, filepath.Join(project, FileName) + ":4"},
+		{filepath.Join(project, "sub", "gen"), false, true, filepath.Join(project, FileName) + ":4"},
+		{filepath.Join(project, "keep.o"), false, false, ""},
+		{filepath.Join(project, "sub", "a.tmp"), false, true, filepath.Join(project, "sub", FileName) + ":1"},
+		{filepath.Join(project, "a.tmp"), false, false, ""},
+	}
+
+	for _, tt := range tests {
+		source, ignored := m.Match(tt.path, tt.isDir)
+		assert.Equal(t, tt.ignored, ignored, tt.path)
+		assert.Equal(t, tt.source, source, tt.path)
+	}
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(filepath.Join(t.TempDir(), "not-exist"))
+	require.NoError(t, err)
+	assert.Empty(t, m.global)
+
+	_, err = parse(strings.NewReader("[\n"), "ignore", "")
+	assert.Error(t, err)
+}
+
+func TestMatchDiscovery(t *testing.T) {
+	tmp := t.TempDir()
+	home := filepath.Join(tmp, "home")
+	project := filepath.Join(home, "project")
+	require.NoError(t, os.MkdirAll(project, 0o755))
+	t.Setenv("HOME", home)
+
+	// above $HOME, not read
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, FileName), []byte("*\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(home, FileName), []byte("*.tmp\n"), 0o644))
+	// writable by others, not read
+	shared := filepath.Join(project, FileName)
+	require.NoError(t, os.WriteFile(shared, []byte("*.o\n"), 0o644))
+	require.NoError(t, os.Chmod(shared, 0o666))
+
+	m, err := New(filepath.Join(tmp, "not-exist"))
+	require.NoError(t, err)
+
+	_, ignored := m.Match(filepath.Join(project, "a.c"), false)
+	assert.False(t, ignored)
+	_, ignored = m.Match(filepath.Join(project, "a.o"), false)
+	assert.False(t, ignored)
+	source, ignored := m.Match(filepath.Join(project, "a.tmp"), false)
+	assert.True(t, ignored)
+	assert.Equal(t, filepath.Join(home, FileName)+":1", source)
+}
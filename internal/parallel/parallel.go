@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Upper limit of Workers, loading trash cans is mostly I/O bound
+const maxWorkers = 16
+
+// Default number of workers
+func Workers() int {
+	return min(runtime.GOMAXPROCS(0)*2, maxWorkers)
+}
+
+// Call fn(i) for each i in [0, n) with at most workers goroutines, and wait for all to finish
+// fn must write its result to the i-th element of a slice to keep the order deterministic.
+func Do(n int, workers int, fn func(i int)) {
+	workers = min(workers, n)
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+}
@@ -0,0 +1,33 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 100} {
+		n := 50
+		results := make([]int, n)
+		var running, maxRunning atomic.Int32
+
+		Do(n, workers, func(i int) {
+			cur := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if cur <= m || maxRunning.CompareAndSwap(m, cur) {
+					break
+				}
+			}
+			results[i] = i * 2
+			running.Add(-1)
+		})
+
+		for i, r := range results {
+			assert.Equal(t, i*2, r)
+		}
+		assert.LessOrEqual(t, int(maxRunning.Load()), max(workers, 1))
+	}
+}
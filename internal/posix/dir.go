@@ -66,3 +66,26 @@ func DirEmpty(name string) (bool, error) {
 	}
 	return false, err
 }
+
+// Device number (st_dev) of the file system containing path
+func DeviceID(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, err
+	}
+	// field type differs by OS
+	return uint64(st.Dev), nil
+}
+
+// same as df -B1, returns available and total size of the file system containing path
+// Available size is for unprivileged users, reserved blocks are not included.
+func DiskSpace(path string) (avail uint64, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+
+	// field types differ by OS
+	bsize := uint64(st.Bsize)
+	return uint64(st.Bavail) * bsize, uint64(st.Blocks) * bsize, nil
+}
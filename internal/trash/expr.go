@@ -0,0 +1,420 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gobwas/glob"
+)
+
+// Filter expression given by --where
+//
+//	expr    = or
+//	or      = and { ("or" | "||") and }
+//	and     = not { ("and" | "&&") not }
+//	not     = ("not" | "!") not | primary
+//	primary = "(" expr ")" | term
+//	term    = field op value | "dir" | "file" | "link"
+//
+// String fields (name, path, trashpath, trashdir) support
+// = (exact), != (not exact), ~ (glob), !~ (not glob), =~ (regex), !=~ (not regex) and contains.
+// size supports =, !=, <, <=, >, >= with human size (e.g. 1MB).
+// date (deletion date) supports the same operators with the notation of --since (e.g. 2d, 2024-01-02).
+// e.g. name ~ "*.go" and size > 1MB and not dir
+type Expr struct {
+	root exprNode
+
+	needSize bool // size is referenced
+	needMode bool // file or link is referenced
+}
+
+type exprNode interface {
+	match(f *File) bool
+}
+
+type (
+	exprAnd struct{ left, right exprNode }
+	exprOr  struct{ left, right exprNode }
+	exprNot struct{ node exprNode }
+)
+
+func (e exprAnd) match(f *File) bool { return e.left.match(f) && e.right.match(f) }
+func (e exprOr) match(f *File) bool  { return e.left.match(f) || e.right.match(f) }
+func (e exprNot) match(f *File) bool { return !e.node.match(f) }
+
+// name, path, trashpath, trashdir
+type exprString struct {
+	field  string
+	op     string
+	value  string
+	glob   glob.Glob
+	regexp *regexp.Regexp
+}
+
+func (e exprString) match(f *File) bool {
+	var s string
+	switch e.field {
+	case "name":
+		s = f.Name
+	case "path":
+		s = f.OriginalPath
+	case "trashpath":
+		s = f.TrashPath
+	case "trashdir":
+		s = f.TrashDir
+	}
+
+	switch e.op {
+	case "=":
+		return s == e.value
+	case "!=":
+		return s != e.value
+	case "~":
+		return e.glob.Match(s)
+	case "!~":
+		return !e.glob.Match(s)
+	case "=~":
+		return e.regexp.MatchString(s)
+	case "!=~":
+		return !e.regexp.MatchString(s)
+	case "contains":
+		return strings.Contains(s, e.value)
+	}
+	return false
+}
+
+type exprSize struct {
+	op   string
+	size int64
+}
+
+func (e exprSize) match(f *File) bool {
+	if f.Size == nil {
+		// size unknown never matches
+		return false
+	}
+	return compare(e.op, *f.Size, e.size)
+}
+
+type exprDate struct {
+	op string
+	t  time.Time
+}
+
+func (e exprDate) match(f *File) bool {
+	return compare(e.op, f.DeletedAt.Unix(), e.t.Unix())
+}
+
+// dir, file, link
+type exprType struct {
+	typ string
+}
+
+func (e exprType) match(f *File) bool {
+	switch e.typ {
+	case "dir":
+		return f.IsDir
+	case "file":
+		return f.Mode.IsRegular()
+	case "link":
+		return f.Mode&fs.ModeSymlink != 0
+	}
+	return false
+}
+
+func compare(op string, a, b int64) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func (e *Expr) Match(f *File) bool {
+	return e.root.match(f)
+}
+
+// Compile the filter expression, relative dates are based on now
+func CompileExpr(s string, now time.Time) (*Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+
+	p := &exprParser{tokens: tokens, now: now, expr: &Expr{}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+
+	p.expr.root = root
+	return p.expr, nil
+}
+
+type token struct {
+	text   string
+	quoted bool // quoted string is never a keyword nor an operator
+}
+
+// longest first
+var exprOperators = []string{"!=~", "&&", "||", "!=", "=~", "!~", "<=", ">=", "==", "=", "~", "<", ">", "!", "(", ")"}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	r := []rune(s)
+
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			// quoted string, backslash escapes the next character
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(r) && r[j] != c; j++ {
+				if r[j] == '\\' && j+1 < len(r) {
+					j++
+				}
+				b.WriteRune(r[j])
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated string: %s", string(r[i:]))
+			}
+			tokens = append(tokens, token{text: b.String(), quoted: true})
+			i = j + 1
+		default:
+			if op, ok := matchOperator(string(r[i:])); ok {
+				i += len([]rune(op))
+				if op == "==" {
+					op = "="
+				}
+				tokens = append(tokens, token{text: op})
+				continue
+			}
+
+			// bare word
+			j := i
+			for ; j < len(r) && !unicode.IsSpace(r[j]) && r[j] != '"' && r[j] != '\''; j++ {
+				if _, ok := matchOperator(string(r[j:])); ok {
+					break
+				}
+			}
+			tokens = append(tokens, token{text: string(r[i:j])})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+func matchOperator(s string) (string, bool) {
+	for _, op := range exprOperators {
+		if strings.HasPrefix(s, op) {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+	expr   *Expr
+}
+
+func (p *exprParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *exprParser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+// Consume the keyword or operator if it is next
+func (p *exprParser) accept(words ...string) bool {
+	t, ok := p.peek()
+	if !ok || t.quoted {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = exprOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and", "&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = exprAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.accept("not", "!") {
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return exprNot{node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, errors.New("missing )")
+		}
+		return node, nil
+	}
+
+	t, ok := p.next()
+	if !ok {
+		return nil, errors.New("unexpected end of expression")
+	}
+	if t.quoted {
+		return nil, fmt.Errorf("field is expected, but got string %q", t.text)
+	}
+
+	field := strings.ToLower(t.text)
+	switch field {
+	case "dir":
+		return exprType{typ: field}, nil
+	case "file", "link":
+		p.expr.needMode = true
+		return exprType{typ: field}, nil
+	}
+
+	op, ok := p.next()
+	if !ok || op.quoted {
+		return nil, fmt.Errorf("operator is expected after %q", t.text)
+	}
+	value, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("value is expected after %q %s", t.text, op.text)
+	}
+
+	switch field {
+	case "name", "path", "trashpath", "trashdir":
+		return newExprString(field, strings.ToLower(op.text), value.text)
+	case "size":
+		if !isCompareOp(op.text) {
+			return nil, fmt.Errorf("size does not support %q", op.text)
+		}
+		size, err := humanize.ParseBytes(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("size unit is invalid: %q", value.text)
+		}
+		p.expr.needSize = true
+		return exprSize{op: op.text, size: int64(size)}, nil
+	case "date":
+		if !isCompareOp(op.text) {
+			return nil, fmt.Errorf("date does not support %q", op.text)
+		}
+		tm, err := ParseTime(value.text, p.now)
+		if err != nil {
+			return nil, err
+		}
+		return exprDate{op: op.text, t: tm}, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q (name, path, trashpath, trashdir, size, date, dir, file, link)", t.text)
+	}
+}
+
+func newExprString(field string, op string, value string) (exprNode, error) {
+	e := exprString{field: field, op: op, value: value}
+
+	switch op {
+	case "=", "!=", "contains":
+	case "~", "!~":
+		g, err := glob.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", value, err)
+		}
+		e.glob = g
+	case "=~", "!=~":
+		r, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", value, err)
+		}
+		e.regexp = r
+	default:
+		return nil, fmt.Errorf("%s does not support %q", field, op)
+	}
+
+	if field == "trashdir" && (op == "=" || op == "!=") {
+		e.value = filepath.Clean(value)
+	}
+
+	return e, nil
+}
+
+func isCompareOp(op string) bool {
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
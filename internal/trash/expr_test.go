@@ -0,0 +1,114 @@
+package trash
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileExpr(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	size := func(n int64) *int64 { return &n }
+
+	goFile := File{
+		Name:         "main.go",
+		OriginalPath: "/home/user/src/main.go",
+		TrashPath:    "/home/user/.local/share/Trash/files/main.go",
+		TrashDir:     "/home/user/.local/share/Trash",
+		DeletedAt:    now.Add(-time.Hour),
+		Size:         size(2_000_000),
+		Mode:         0o644,
+	}
+	dir := File{
+		Name:         "build",
+		OriginalPath: "/home/user/src/build",
+		TrashDir:     "/mnt/.Trash-1000",
+		DeletedAt:    now.AddDate(0, 0, -10),
+		IsDir:        true,
+		Mode:         fs.ModeDir | 0o755,
+	}
+	link := File{
+		Name:         "latest",
+		OriginalPath: "/home/user/latest",
+		DeletedAt:    now.AddDate(0, 0, -1),
+		Size:         size(10),
+		Mode:         fs.ModeSymlink | 0o777,
+	}
+
+	tests := []struct {
+		expr string
+		want []string // names matched
+	}{
+		{expr: `name ~ "*.go" and size > 1MB and not dir`, want: []string{"main.go"}},
+		{expr: `name ~ *.go`, want: []string{"main.go"}},
+		{expr: `name = build`, want: []string{"build"}},
+		{expr: `name == "build"`, want: []string{"build"}},
+		{expr: `name != build`, want: []string{"main.go", "latest"}},
+		{expr: `name !~ '*.go'`, want: []string{"build", "latest"}},
+		{expr: `path =~ "^/home/user/src/"`, want: []string{"main.go", "build"}},
+		{expr: `path !=~ "src"`, want: []string{"latest"}},
+		{expr: `path contains "src/b"`, want: []string{"build"}},
+		{expr: `trashdir = /mnt/.Trash-1000/`, want: []string{"build"}},
+		{expr: `trashpath ~ "*/files/*"`, want: []string{"main.go"}},
+		{expr: `size <= 10`, want: []string{"latest"}},
+		{expr: `size >= 0`, want: []string{"main.go", "latest"}},
+		{expr: `date > 2d`, want: []string{"main.go", "latest"}},
+		{expr: `date < 2024-03-05`, want: []string{"build"}},
+		{expr: `dir or link`, want: []string{"build", "latest"}},
+		{expr: `file`, want: []string{"main.go"}},
+		{expr: `!dir && !link`, want: []string{"main.go"}},
+		{expr: `dir || name ~ "*.go" && size > 1MB`, want: []string{"main.go", "build"}},
+		{expr: `(dir or name ~ "*.go") and date > 2d`, want: []string{"main.go"}},
+		{expr: `NOT (dir OR link)`, want: []string{"main.go"}},
+		{expr: `name = "and"`, want: nil},
+	}
+
+	for _, tt := range tests {
+		e, err := CompileExpr(tt.expr, now)
+		require.NoError(t, err, tt.expr)
+
+		var got []string
+		for _, f := range []File{goFile, dir, link} {
+			if e.Match(&f) {
+				got = append(got, f.Name)
+			}
+		}
+		assert.Equal(t, tt.want, got, tt.expr)
+	}
+
+	e, err := CompileExpr(`size > 1MB or link`, now)
+	require.NoError(t, err)
+	assert.True(t, e.needSize)
+	assert.True(t, e.needMode)
+
+	e, err = CompileExpr(`name ~ "*.go" or dir`, now)
+	require.NoError(t, err)
+	assert.False(t, e.needSize)
+	assert.False(t, e.needMode)
+}
+
+func TestCompileExprError(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`name`,
+		`name ~`,
+		`foo = bar`,
+		`name < a`,
+		`size ~ 1MB`,
+		`size > 1XB`,
+		`date > yesterday2`,
+		`path =~ "["`,
+		`(dir`,
+		`dir)`,
+		`dir link`,
+		`name = "abc`,
+		`"name" = abc`,
+		`dir and`,
+	} {
+		_, err := CompileExpr(expr, time.Now())
+		assert.Error(t, err, expr)
+	}
+}
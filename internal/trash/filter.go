@@ -0,0 +1,111 @@
+package trash
+
+import (
+	"fmt"
+	"io/fs"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// --type
+var FileTypes = []string{"f", "d", "l"}
+
+func (b *Box) hasAttrFilters() bool {
+	return len(b.types) > 0 || len(b.exts) > 0 || b.owner != ""
+}
+
+// lstat(2) is required for --type, --owner and file or link in --where
+func (b *Box) needLstat() bool {
+	return len(b.types) > 0 || b.owner != "" || (b.whereExpr != nil && b.whereExpr.needMode)
+}
+
+func (b *Box) checkAttrFilters() error {
+	for _, t := range b.types {
+		if !slices.Contains(FileTypes, t) {
+			return fmt.Errorf("--type must be f, d or l: %q", t)
+		}
+	}
+
+	exts := make([]string, 0, len(b.exts))
+	for _, e := range b.exts {
+		e = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(e), "."))
+		if e == "" {
+			return fmt.Errorf("--ext must not be empty")
+		}
+		exts = append(exts, e)
+	}
+	b.exts = exts
+
+	if b.owner != "" {
+		uid, err := lookupUid(b.owner)
+		if err != nil {
+			return fmt.Errorf("--owner: %w", err)
+		}
+		b.ownerUid = uid
+	}
+
+	return nil
+}
+
+// Returns uid of user name or uid
+func lookupUid(owner string) (uint32, error) {
+	if uid, err := strconv.ParseUint(owner, 10, 32); err == nil {
+		return uint32(uid), nil
+	}
+
+	u, err := user.Lookup(owner)
+	if err != nil {
+		return 0, err
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected uid %q of user %q", u.Uid, owner)
+	}
+	return uint32(uid), nil
+}
+
+func matchType(mode fs.FileMode, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "f":
+			if mode.IsRegular() {
+				return true
+			}
+		case "d":
+			if mode.IsDir() {
+				return true
+			}
+		case "l":
+			if mode&fs.ModeSymlink != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Case insensitive, "tar.gz" matches "a.tar.gz"
+func matchExt(name string, exts []string) bool {
+	name = strings.ToLower(name)
+	for _, e := range exts {
+		if strings.HasSuffix(name, "."+e) && len(name) > len(e)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns uid of the file, false if unknown
+func fileOwner(fi fs.FileInfo) (uint32, bool) {
+	if fi == nil {
+		return 0, false
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return st.Uid, true
+}
@@ -0,0 +1,87 @@
+package trash
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/xdg"
+)
+
+func TestMatchExt(t *testing.T) {
+	exts := []string{"log", "tar.gz"}
+
+	assert.True(t, matchExt("a.log", exts))
+	assert.True(t, matchExt("A.LOG", exts))
+	assert.True(t, matchExt("a.tar.gz", exts))
+	assert.False(t, matchExt("a.gz", exts))
+	assert.False(t, matchExt("alog", exts))
+	assert.False(t, matchExt(".log", exts), "dotfile has no extension")
+}
+
+func TestMatchType(t *testing.T) {
+	assert.True(t, matchType(0o644, []string{"f"}))
+	assert.False(t, matchType(0o644, []string{"d", "l"}))
+	assert.True(t, matchType(fs.ModeDir|0o755, []string{"d"}))
+	assert.True(t, matchType(fs.ModeSymlink|0o777, []string{"f", "l"}))
+	assert.False(t, matchType(fs.ModeNamedPipe|0o644, []string{"f", "d", "l"}))
+}
+
+func TestCheckAttrFilters(t *testing.T) {
+	b := NewBox(WithTypes([]string{"f", "d"}), WithExts([]string{".LOG", "txt"}), WithOwner("0"))
+	require.NoError(t, b.checkAttrFilters())
+	assert.Equal(t, []string{"log", "txt"}, b.exts)
+	assert.Equal(t, uint32(0), b.ownerUid)
+
+	for _, opt := range []BoxOption{
+		WithTypes([]string{"x"}),
+		WithExts([]string{"."}),
+		WithOwner("no-such-user-gtrash"),
+	} {
+		b := NewBox(opt)
+		assert.Error(t, b.checkAttrFilters())
+	}
+}
+
+func TestGetFilesAttrFilter(t *testing.T) {
+	trashDir := xdg.NewTrashDirManual(t.TempDir())
+	require.NoError(t, trashDir.CreateDir())
+
+	writeInfo := func(name string) {
+		info := "[Trash Info]\nPath=/tmp/" + name + "\nDeletionDate=2024-01-01T00:00:00\n"
+		require.NoError(t, os.WriteFile(filepath.Join(trashDir.InfoDir(), name+".trashinfo"), []byte(info), 0o600))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(trashDir.FilesDir(), "a.log"), nil, 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(trashDir.FilesDir(), "dir.log"), 0o700))
+	require.NoError(t, os.Symlink("a.log", filepath.Join(trashDir.FilesDir(), "link")))
+	for _, name := range []string{"a.log", "dir.log", "link"} {
+		writeInfo(name)
+	}
+
+	infoDirents, err := os.ReadDir(trashDir.InfoDir())
+	require.NoError(t, err)
+	fileEntries := map[string]bool{"a.log": false, "dir.log": true, "link": false}
+
+	names := func(opts ...BoxOption) []string {
+		b := NewBox(opts...)
+		require.NoError(t, b.checkOptions())
+		files, _ := b.getFiles(infoDirents, fileEntries, trashDir, xdg.NewInfoIndex(trashDir.Dir), &syncDirCache{})
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"a.log"}, names(WithTypes([]string{"f"})))
+	assert.Equal(t, []string{"dir.log", "link"}, names(WithTypes([]string{"d", "l"})))
+	assert.Equal(t, []string{"a.log", "dir.log"}, names(WithExts([]string{"log"})))
+	assert.Equal(t, []string{"dir.log"}, names(WithExts([]string{"log"}), WithTypes([]string{"d"})))
+	assert.Equal(t, []string{"a.log", "dir.log", "link"}, names(WithOwner(strconv.Itoa(os.Getuid()))))
+	assert.Empty(t, names(WithOwner(strconv.Itoa(os.Getuid()+1))))
+}
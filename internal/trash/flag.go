@@ -3,6 +3,7 @@ package trash
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -116,3 +117,57 @@ func (s ModeByType) String() string {
 func (s ModeByType) Type() string {
 	return "regex|glob|literal|full"
 }
+
+// --since, --until
+
+var _ pflag.Value = (*TimeFlag)(nil)
+
+// Parsed by ParseTime when set, zero if not set
+// An empty string resets it, e.g. to override the config file.
+type TimeFlag struct {
+	Time time.Time
+	str  string
+}
+
+func (t *TimeFlag) Set(str string) error {
+	if str == "" {
+		*t = TimeFlag{}
+		return nil
+	}
+	parsed, err := ParseTime(str, time.Now())
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	t.str = str
+	return nil
+}
+
+func (t *TimeFlag) String() string {
+	return t.str
+}
+
+func (t *TimeFlag) Type() string {
+	return "time"
+}
+
+var _ pflag.Value = (*UntilFlag)(nil)
+
+// Same as TimeFlag, but a day is parsed as the end of the day by ParseTimeEnd
+type UntilFlag struct {
+	TimeFlag
+}
+
+func (t *UntilFlag) Set(str string) error {
+	if str == "" {
+		t.TimeFlag = TimeFlag{}
+		return nil
+	}
+	parsed, err := ParseTimeEnd(str, time.Now())
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	t.str = str
+	return nil
+}
@@ -0,0 +1,123 @@
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/umlx5h/gtrash/internal/posix"
+)
+
+// Lines longer than this are not searched
+const maxGrepLineSize = 1024 * 1024
+
+// Line matched by Grep
+type GrepMatch struct {
+	Path string // original path, under OriginalPath if the trashed file is a directory
+	Line int    // 1-based
+	Text string
+}
+
+// Search the content of the trashed file line by line.
+// If it is a directory, regular files inside are searched recursively.
+// Binary files and symbolic links are skipped.
+// If firstOnly is true, it stops at the first matched line.
+func (f *File) Grep(re *regexp.Regexp, firstOnly bool) ([]GrepMatch, error) {
+	fi, err := os.Lstat(f.TrashPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.Mode().IsRegular() {
+		return grepFile(f.TrashPath, f.OriginalPath, re, firstOnly)
+	}
+	if !fi.IsDir() {
+		return nil, nil
+	}
+
+	var matches []GrepMatch
+	err = filepath.WalkDir(f.TrashPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == f.TrashPath {
+				return err
+			}
+			// Even if rename(2) succeeds, the file inside may not be readable depending on the permissions.
+			slog.Warn("cannot read in the trashed directory", "path", path, "error", err)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.TrashPath, path)
+		if err != nil {
+			return err
+		}
+		m, err := grepFile(path, filepath.Join(f.OriginalPath, rel), re, firstOnly)
+		if err != nil {
+			slog.Warn("cannot search the file", "path", path, "error", err)
+			return nil
+		}
+		matches = append(matches, m...)
+
+		if firstOnly && len(matches) > 0 {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func grepFile(path string, originalPath string, re *regexp.Regexp, firstOnly bool) ([]GrepMatch, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() == 0 {
+		return nil, nil
+	}
+
+	if binary, err := posix.IsBinary(f, fi.Size()); err != nil {
+		return nil, err
+	} else if binary {
+		slog.Debug("skipped binary file", "path", path)
+		return nil, nil
+	}
+
+	var matches []GrepMatch
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, maxGrepLineSize)
+	for n := 1; s.Scan(); n++ {
+		if !re.Match(s.Bytes()) {
+			continue
+		}
+		matches = append(matches, GrepMatch{Path: originalPath, Line: n, Text: s.Text()})
+		if firstOnly {
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			// return lines matched so far
+			slog.Warn("skipped the rest of the file due to a too long line", "path", path)
+			return matches, nil
+		}
+		return nil, err
+	}
+
+	return matches, nil
+}
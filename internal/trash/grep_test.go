@@ -0,0 +1,61 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrep(t *testing.T) {
+	files := t.TempDir()
+	write := func(path string, content string) {
+		path = filepath.Join(files, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	write("a.txt", "foo\nTODO: a\nTODO: b\n")
+	write("dir/sub/b.md", "bar\nnothing todo\n")
+	write("dir/c.txt", "TODO: c")
+	write("dir/bin", "\x00\x01TODO")
+	write("dir/empty", "")
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(files, "dir/link")))
+
+	file := File{OriginalPath: "/home/user/a.txt", TrashPath: filepath.Join(files, "a.txt")}
+	dir := File{OriginalPath: "/home/user/dir", TrashPath: filepath.Join(files, "dir"), IsDir: true}
+	re := regexp.MustCompile(`(?i)todo`)
+
+	matches, err := file.Grep(re, false)
+	require.NoError(t, err)
+	assert.Equal(t, []GrepMatch{
+		{Path: "/home/user/a.txt", Line: 2, Text: "TODO: a"},
+		{Path: "/home/user/a.txt", Line: 3, Text: "TODO: b"},
+	}, matches)
+
+	matches, err = file.Grep(re, true)
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	// binary and symbolic link are skipped
+	matches, err = dir.Grep(re, false)
+	require.NoError(t, err)
+	assert.Equal(t, []GrepMatch{
+		{Path: "/home/user/dir/c.txt", Line: 1, Text: "TODO: c"},
+		{Path: "/home/user/dir/sub/b.md", Line: 2, Text: "nothing todo"},
+	}, matches)
+
+	matches, err = dir.Grep(re, true)
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	matches, err = dir.Grep(regexp.MustCompile(`notfound`), false)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = (&File{TrashPath: filepath.Join(files, "missing")}).Grep(re, false)
+	assert.Error(t, err)
+}
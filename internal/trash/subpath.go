@@ -0,0 +1,86 @@
+package trash
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Returns the file at rel inside the trashed directory
+// The returned file shares .trashinfo with the directory, see File.Delete.
+func (f *File) SubFile(rel string) (File, error) {
+	rel = filepath.Clean(rel)
+	if filepath.IsAbs(rel) || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return File{}, fmt.Errorf("invalid sub path: %q", rel)
+	}
+
+	trashPath := filepath.Join(f.TrashPath, rel)
+	fi, err := os.Lstat(trashPath)
+	if err != nil {
+		return File{}, err
+	}
+
+	sub := *f
+	sub.Name = filepath.Base(rel)
+	sub.OriginalPath = filepath.Join(f.OriginalPath, rel)
+	sub.TrashPath = trashPath
+	sub.SubPath = filepath.Join(f.SubPath, rel)
+	sub.IsDir = fi.IsDir()
+	sub.Mode = fi.Mode()
+	sub.Size = nil
+	if fi.Mode().IsRegular() {
+		s := fi.Size()
+		sub.Size = &s
+	}
+
+	return sub, nil
+}
+
+// Relative path of path inside dir, ok is false if path is not under dir
+func subPathOf(dir string, path string) (rel string, ok bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+func (b *Box) hasSubPathQuery(dir string) bool {
+	for _, q := range b.queries {
+		if _, ok := subPathOf(dir, q); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace the trashed directory with the files inside it specified by queries
+// Must be safe to call concurrently, do not modify Box.
+func (b *Box) expandSubPaths(f File) []File {
+	if !b.subPath || b.queryModeBy != ModeByFull || len(b.queries) == 0 || !f.IsDir {
+		return []File{f}
+	}
+
+	var files []File
+	if slices.Contains(b.queries, f.OriginalPath) {
+		files = append(files, f)
+	}
+
+	for _, q := range b.queries {
+		rel, ok := subPathOf(f.OriginalPath, q)
+		if !ok {
+			continue
+		}
+		sub, err := f.SubFile(rel)
+		if err != nil {
+			slog.Debug("not found in the trashed directory", "trashPath", f.TrashPath, "subPath", rel, "error", err)
+			continue
+		}
+		files = append(files, sub)
+	}
+
+	return files
+}
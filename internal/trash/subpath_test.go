@@ -0,0 +1,88 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubFile(t *testing.T) {
+	tmp := t.TempDir()
+	trashPath := filepath.Join(tmp, "files", "project")
+	infoPath := filepath.Join(tmp, "info", "project.trashinfo")
+	require.NoError(t, os.MkdirAll(filepath.Join(trashPath, "src"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(infoPath), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(trashPath, "src", "main.go"), []byte("package main\n"), 0o644))
+	require.NoError(t, os.WriteFile(infoPath, nil, 0o644))
+
+	dir := File{
+		Name:          "project",
+		OriginalPath:  "/home/user/project",
+		TrashPath:     trashPath,
+		TrashInfoPath: infoPath,
+		IsDir:         true,
+	}
+
+	sub, err := dir.SubFile("src/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go", sub.Name)
+	assert.Equal(t, "/home/user/project/src/main.go", sub.OriginalPath)
+	assert.Equal(t, filepath.Join(trashPath, "src", "main.go"), sub.TrashPath)
+	assert.Equal(t, infoPath, sub.TrashInfoPath)
+	assert.Equal(t, "src/main.go", sub.SubPath)
+	assert.False(t, sub.IsDir)
+	require.NotNil(t, sub.Size)
+	assert.EqualValues(t, 13, *sub.Size)
+
+	src, err := dir.SubFile("src/")
+	require.NoError(t, err)
+	assert.True(t, src.IsDir)
+	sub, err = src.SubFile("main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "src/main.go", sub.SubPath, "nested")
+
+	for _, rel := range []string{"", ".", "..", "../x", "/etc/passwd", "src/missing"} {
+		_, err := dir.SubFile(rel)
+		assert.Error(t, err, rel)
+	}
+
+	t.Run("delete keeps trashinfo", func(t *testing.T) {
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(infoPath, old, old))
+
+		require.NoError(t, sub.Delete())
+		fi, err := os.Stat(infoPath)
+		require.NoError(t, err)
+		assert.True(t, fi.ModTime().After(old), "mtime is updated")
+	})
+
+	t.Run("expand", func(t *testing.T) {
+		b := NewBox(
+			WithQueries([]string{"/home/user/project/src/main.go", "/home/user/project/missing", "/home/user/other"}),
+			WithQueryMode(ModeByFull),
+			WithSubPath(true),
+		)
+		require.True(t, b.hasSubPathQuery(dir.OriginalPath))
+		files := b.expandSubPaths(dir)
+		require.Len(t, files, 1)
+		assert.Equal(t, "/home/user/project/src/main.go", files[0].OriginalPath)
+
+		b = NewBox(
+			WithQueries([]string{"/home/user/project", "/home/user/project/src"}),
+			WithQueryMode(ModeByFull),
+			WithSubPath(true),
+		)
+		files = b.expandSubPaths(dir)
+		require.Len(t, files, 2)
+		assert.Equal(t, "", files[0].SubPath)
+		assert.Equal(t, "src", files[1].SubPath)
+
+		// no queries, all files are listed as is
+		b = NewBox(WithQueryMode(ModeByFull), WithSubPath(true))
+		assert.Equal(t, []File{dir}, b.expandSubPaths(dir))
+	})
+}
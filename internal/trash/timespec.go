@@ -0,0 +1,100 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of absolute time, in local time
+var timeLayouts = []string{
+	time.DateOnly,
+	"2006-01-02 15:04",
+	time.DateTime,
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// e.g. 90m, 2h, 3d, 1w2d
+var durationRe = regexp.MustCompile(`^(\d+(s|m|h|d|w))+$`)
+
+var durationPartRe = regexp.MustCompile(`(\d+)(s|m|h|d|w)`)
+
+var durationUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// Parse the argument of --since and --until
+//
+//   - duration before now: 90m, 2h, 3d, 3w, 1w2d (s, m, h, d and w are supported)
+//   - date or timestamp in local time: 2024-01-02, 2024-01-02 15:04, 2024-01-02T15:04:05, RFC 3339
+//   - today, yesterday: the beginning of the day
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("empty time")
+	}
+
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		y, m, d := now.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+	case "yesterday":
+		y, m, d := now.Date()
+		return time.Date(y, m, d-1, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	if durationRe.MatchString(s) {
+		var d time.Duration
+		for _, part := range durationPartRe.FindAllStringSubmatch(s, -1) {
+			n, err := strconv.ParseInt(part[1], 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid duration: %q", s)
+			}
+			d += time.Duration(n) * durationUnits[part[2]]
+		}
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %q (e.g. 2h, 3d, 2w, 2024-01-02, '2024-01-02 15:04')", s)
+}
+
+// Same as ParseTime, but a day (date without time, today or yesterday) is the end of the day
+// Used for --until, so that e.g. --until 2024-05-01 includes files deleted on May 1.
+func ParseTimeEnd(s string, now time.Time) (time.Time, error) {
+	t, err := ParseTime(s, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if isDay(s, now) {
+		// inclusive
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return t, nil
+}
+
+func isDay(s string, now time.Time) bool {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "today", "yesterday":
+		return true
+	}
+	_, err := time.ParseInLocation(time.DateOnly, s, now.Location())
+	return err == nil
+}
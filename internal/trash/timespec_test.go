@@ -0,0 +1,88 @@
+package trash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{in: "90m", want: now.Add(-90 * time.Minute)},
+		{in: "2h", want: now.Add(-2 * time.Hour)},
+		{in: "3d", want: now.AddDate(0, 0, -3)},
+		{in: "3w", want: now.AddDate(0, 0, -21)},
+		{in: "1w2d", want: now.AddDate(0, 0, -9)},
+		{in: "1h30m", want: now.Add(-90 * time.Minute)},
+		{in: "now", want: now},
+		{in: "today", want: time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)},
+		{in: "Yesterday", want: time.Date(2024, 3, 9, 0, 0, 0, 0, time.Local)},
+		{in: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)},
+		{in: "2024-01-02 15:04", want: time.Date(2024, 1, 2, 15, 4, 0, 0, time.Local)},
+		{in: "2024-01-02 15:04:05", want: time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)},
+		{in: "2024-01-02T15:04:05", want: time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)},
+		{in: "2024-01-02T15:04:05Z", want: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		require.NoError(t, err, tt.in)
+		assert.True(t, tt.want.Equal(got), "%s: want %s, got %s", tt.in, tt.want, got)
+	}
+
+	for _, in := range []string{"", "3", "3y", "-3d", "d3", "2024-13-01", "2024/01/02"} {
+		_, err := ParseTime(in, now)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseTimeEnd(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{in: "2024-05-01", want: time.Date(2024, 5, 1, 23, 59, 59, 999999999, time.Local)},
+		{in: "today", want: time.Date(2024, 3, 10, 23, 59, 59, 999999999, time.Local)},
+		{in: "yesterday", want: time.Date(2024, 3, 9, 23, 59, 59, 999999999, time.Local)},
+		// not a day
+		{in: "2024-05-01 00:00", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{in: "3d", want: now.AddDate(0, 0, -3)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimeEnd(tt.in, now)
+		require.NoError(t, err, tt.in)
+		assert.True(t, tt.want.Equal(got), "%s: want %s, got %s", tt.in, tt.want, got)
+	}
+
+	// files deleted on the day are included
+	var until UntilFlag
+	require.NoError(t, until.Set("2024-05-01"))
+	b := NewBox(WithTimeRange(time.Time{}, until.Time))
+	require.NoError(t, b.checkOptions())
+	assert.False(t, b.until.Before(time.Date(2024, 5, 1, 18, 0, 0, 0, time.Local)))
+	assert.True(t, b.until.Before(time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)))
+}
+
+func TestTimeRange(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)
+
+	b := NewBox(WithDay(7, 0), WithTimeRange(since, until))
+	require.NoError(t, b.checkOptions())
+	assert.Equal(t, since, b.since, "takes precedence over WithDay")
+	assert.Equal(t, until, b.until)
+	assert.False(t, b.noFilterApply)
+
+	b = NewBox(WithTimeRange(until, since))
+	assert.Error(t, b.checkOptions(), "since is after until")
+}
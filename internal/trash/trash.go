@@ -11,12 +11,14 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
 	"github.com/gobwas/glob"
 	"github.com/spf13/pflag"
+	"github.com/umlx5h/gtrash/internal/parallel"
 	"github.com/umlx5h/gtrash/internal/posix"
 	"github.com/umlx5h/gtrash/internal/xdg"
 )
@@ -37,6 +39,7 @@ type Box struct {
 	TrashDirs       []string
 	hitByPath       map[string]int // key: originalPath, value: number of files to hit
 	OrphanMeta      []File         // .trashinfo exists but there is no real file in the files folder
+	OrphanFiles     []File         // file exists in the files folder but there is no .trashinfo, OriginalPath is empty
 
 	// set by cli flags
 
@@ -51,11 +54,20 @@ type Box struct {
 	queriesReg  []*regexp.Regexp
 	queriesGlob []glob.Glob
 	queryModeBy ModeByType
+	subPath     bool // with ModeByFull, queries can also point inside trashed directories
 
-	// filter by date
-	day      int
-	dayPoint time.Time // --day-new, --day-old
-	newer    bool
+	// filter by deletion date, zero means unlimited
+	since time.Time // --since, --day-new
+	until time.Time // --until, --day-old
+
+	// filter by file attributes
+	types    []string // --type, f: regular file, d: directory, l: symbolic link
+	exts     []string // --ext, lower case without leading dot
+	owner    string   // --owner, user name or uid
+	ownerUid uint32   // resolved from owner
+
+	where     string // --where, filter expression
+	whereExpr *Expr  // compiled from where
 
 	// filter by size
 	size       uint64 // byte, convert from sizeHuman
@@ -66,6 +78,7 @@ type Box struct {
 
 	// Whether to use stat(2) to get size and mode
 	GetSize       bool
+	getMode       bool // use lstat(2) to get mode without size
 	noFilterApply bool // true if select all trashcan
 
 	limitLast int
@@ -128,15 +141,61 @@ func WithQueryMode(mode ModeByType) BoxOption {
 	}
 }
 
-// TODO: Support for notations other than day?
+func WithSubPath(subPath bool) BoxOption {
+	return func(b *Box) {
+		b.subPath = subPath
+	}
+}
+
+// Filter by files deleted within dayNew days and before dayOld days
+// 0 means unlimited.
 func WithDay(dayNew int, dayOld int) BoxOption {
-	day := max(dayNew, dayOld) // either specified
-	point := time.Now().AddDate(0, 0, -day)
+	now := time.Now()
+
+	return func(b *Box) {
+		if dayNew > 0 {
+			b.since = now.AddDate(0, 0, -dayNew)
+		}
+		if dayOld > 0 {
+			b.until = now.AddDate(0, 0, -dayOld)
+		}
+	}
+}
+
+// Filter by files deleted between since and until (inclusive)
+// Zero time means unlimited. Takes precedence over WithDay if specified.
+func WithTimeRange(since time.Time, until time.Time) BoxOption {
+	return func(b *Box) {
+		if !since.IsZero() {
+			b.since = since
+		}
+		if !until.IsZero() {
+			b.until = until
+		}
+	}
+}
+
+func WithTypes(types []string) BoxOption {
+	return func(b *Box) {
+		b.types = types
+	}
+}
+
+func WithExts(exts []string) BoxOption {
+	return func(b *Box) {
+		b.exts = exts
+	}
+}
+
+func WithOwner(owner string) BoxOption {
+	return func(b *Box) {
+		b.owner = owner
+	}
+}
 
+func WithWhere(where string) BoxOption {
 	return func(b *Box) {
-		b.day = day
-		b.dayPoint = point
-		b.newer = dayNew > 0
+		b.where = where
 	}
 }
 
@@ -166,6 +225,13 @@ func WithGetSize(get bool) BoxOption {
 	}
 }
 
+// Get mode even if sizes are not calculated, e.g. for files no longer in the trash can when output
+func WithGetMode(get bool) BoxOption {
+	return func(b *Box) {
+		b.getMode = get
+	}
+}
+
 // validate and adjust options
 func (b *Box) checkOptions() error {
 	var err error
@@ -247,8 +313,27 @@ func (b *Box) checkOptions() error {
 		}
 	}
 
+	if err := b.checkAttrFilters(); err != nil {
+		return err
+	}
+
+	if b.where != "" {
+		expr, err := CompileExpr(b.where, time.Now())
+		if err != nil {
+			return fmt.Errorf("--where: %w", err)
+		}
+		b.whereExpr = expr
+		if expr.needSize {
+			b.GetSize = true
+		}
+	}
+
+	if !b.since.IsZero() && !b.until.IsZero() && b.since.After(b.until) {
+		return fmt.Errorf("the start of the date range %s is after the end %s", b.since.Format(time.DateTime), b.until.Format(time.DateTime))
+	}
+
 	// check if select all trashcan
-	if len(b.queries) == 0 && b.sizeHuman == "" && b.day == 0 && b.directory == "" {
+	if len(b.queries) == 0 && b.sizeHuman == "" && b.since.IsZero() && b.until.IsZero() && b.directory == "" && !b.hasAttrFilters() && b.where == "" {
 		b.noFilterApply = true
 	}
 
@@ -257,6 +342,10 @@ func (b *Box) checkOptions() error {
 
 var ErrNotFound = errors.New("not found")
 
+// Maximum number of trash cans loaded concurrently
+// Each trash can also loads files concurrently.
+const maxTrashDirWorkers = 4
+
 func (b *Box) Open() error {
 	// validation Box options
 	if err := b.checkOptions(); err != nil {
@@ -280,337 +369,545 @@ func (b *Box) Open() error {
 		trashDirs = []xdg.TrashDir{xdg.NewTrashDirManual(b.trashDir)}
 	}
 
-	for _, trashDir := range trashDirs {
-		slog.Debug("starting to read trashDir", "trashDir", trashDir.Dir)
-		// Scan the files directory to check for the existence of files.
-		// Whether the file is a directory or not can be obtained at this stage.
-		dirents, err := os.ReadDir(trashDir.FilesDir())
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				slog.Warn("cannot read files folder in trashDir, skipped", "trashDir", trashDir, "error", err)
-				continue
-			}
-			// files folder not exists in this mountpoint
-			slog.Debug("not found files folder in trashDir, skipped", "trashDir", trashDir)
+	// Trash cans are usually on different devices, so load them concurrently
+	results := make([]trashDirResult, len(trashDirs))
+	parallel.Do(len(trashDirs), maxTrashDirWorkers, func(i int) {
+		results[i] = b.loadTrashDir(trashDirs[i])
+	})
+
+	// merge in the order of trashDirs to be deterministic
+	for i, r := range results {
+		if !r.loaded {
 			continue
 		}
-		// convert to slices to map
-		fileEntries := make(map[string]bool, len(dirents)) // key: filename, value: isDir
-		for _, ent := range dirents {
-			fileEntries[ent.Name()] = ent.IsDir()
+		trashDir := trashDirs[i]
+
+		for _, f := range r.files {
+			b.hitByPath[f.OriginalPath]++
 		}
+		b.OrphanMeta = append(b.OrphanMeta, r.orphanMeta...)
+		b.OrphanFiles = append(b.OrphanFiles, r.orphanFiles...)
 
-		dirents, err = os.ReadDir(trashDir.InfoDir())
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				slog.Warn("cannot read info folder in trashDir, skipped", "trashDir", trashDir, "error", err)
-				continue
-			}
+		b.TrashDirs = append(b.TrashDirs, trashDir.Dir)
+		if len(r.files) > 0 {
+			b.FilesByTrashDir[trashDir.Dir] = r.files
+		}
+		b.Files = append(b.Files, r.files...)
+	}
 
-			slog.Debug("not found info folder in trashDir, skipped", "trashDir", trashDir)
-			continue
+	if len(b.Files) == 0 {
+		return fmt.Errorf("%w: trashed files", ErrNotFound)
+	}
+
+	sortFiles(b.Files, b.sortBy, b.ascend)
+
+	// truncate to last n items
+	if b.limitLast > 0 {
+		if len(b.Files) > b.limitLast {
+			n := len(b.Files)
+			b.Files = b.Files[n-b.limitLast : n]
 		}
+	}
 
-		// Load directory size cache into map
-		// Not used when nil.
-		var dirCache xdg.DirCache // key: directory name, value: cache entry
+	return nil
+}
 
-		directorySizesPath := filepath.Join(trashDir.Dir, "directorysizes")
+// os.ReadDir bounded by [mount] timeout, the trash can may be on a hung network file system
+func readDir(trashDir xdg.TrashDir, dir string) ([]os.DirEntry, error) {
+	type result struct {
+		dirents []os.DirEntry
+		err     error
+	}
+	r, ok := xdg.WithMountTimeout(trashDir.Root, func() result {
+		dirents, err := os.ReadDir(dir)
+		return result{dirents, err}
+	})
+	if !ok {
+		return nil, errors.New("not responding within [mount] timeout")
+	}
+	return r.dirents, r.err
+}
 
-		if b.GetSize {
-			// init map
-			dirCache = make(xdg.DirCache)
+// Files loaded from one trash can
+type trashDirResult struct {
+	loaded      bool // false if the files or info folder cannot be read
+	files       []File
+	orphanMeta  []File
+	orphanFiles []File
+}
 
-			slog.Debug("reading directorysizes cache", "path", directorySizesPath)
-			if f, err := os.Open(directorySizesPath); err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					slog.Debug("not found directorysizes cache", "path", directorySizesPath, "error", err)
-				} else {
-					slog.Warn("failed to read directorysizes cache", "path", directorySizesPath)
-				}
+// Load files in trashDir
+// Must be safe to call concurrently, do not modify Box.
+func (b *Box) loadTrashDir(trashDir xdg.TrashDir) trashDirResult {
+	slog.Debug("starting to read trashDir", "trashDir", trashDir.Dir)
+	// Scan the files directory to check for the existence of files.
+	// Whether the file is a directory or not can be obtained at this stage.
+	dirents, err := readDir(trashDir, trashDir.FilesDir())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("cannot read files folder in trashDir, skipped", "trashDir", trashDir, "error", err)
+			return trashDirResult{}
+		}
+		// files folder not exists in this mountpoint
+		slog.Debug("not found files folder in trashDir, skipped", "trashDir", trashDir)
+		return trashDirResult{}
+	}
+	// convert to slices to map
+	fileEntries := make(map[string]bool, len(dirents)) // key: filename, value: isDir
+	for _, ent := range dirents {
+		fileEntries[ent.Name()] = ent.IsDir()
+	}
+
+	dirents, err = readDir(trashDir, trashDir.InfoDir())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("cannot read info folder in trashDir, skipped", "trashDir", trashDir, "error", err)
+			return trashDirResult{}
+		}
+
+		slog.Debug("not found info folder in trashDir, skipped", "trashDir", trashDir)
+		return trashDirResult{}
+	}
+
+	// Load directory size cache into map
+	// Not used when nil.
+	var cache xdg.DirCache // key: directory name, value: cache entry
+
+	directorySizesPath := filepath.Join(trashDir.Dir, "directorysizes")
+
+	if b.GetSize {
+		// init map
+		cache = make(xdg.DirCache)
+
+		slog.Debug("reading directorysizes cache", "path", directorySizesPath)
+		if f, err := os.Open(directorySizesPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				slog.Debug("not found directorysizes cache", "path", directorySizesPath, "error", err)
 			} else {
-				if c, err := xdg.NewDirCache(f); err != nil {
-					slog.Warn("failed to parse directorysizes cache, it will be recreated", "path", directorySizesPath, "error", err)
-				} else {
-					// got cache from file
-					dirCache = c
-				}
-				f.Close()
+				slog.Warn("failed to read directorysizes cache", "path", directorySizesPath)
+			}
+		} else {
+			if c, err := xdg.NewDirCache(f); err != nil {
+				slog.Warn("failed to parse directorysizes cache, it will be recreated", "path", directorySizesPath, "error", err)
+			} else {
+				// got cache from file
+				cache = c
 			}
+			f.Close()
 		}
+	}
 
-		slog.Debug("starting to read directory entries", "file_entries", len(fileEntries), "info_entries", len(dirents))
+	slog.Debug("starting to read directory entries", "file_entries", len(fileEntries), "info_entries", len(dirents))
 
-		// True if the cache expires or an entry is added.
-		var dirCacheUpdated bool
+	dirCache := &syncDirCache{cache: cache}
 
-		files := b.getFiles(dirents, fileEntries, trashDir, dirCache, &dirCacheUpdated)
-		slog.Debug("found trashed files", "number", len(files), "trashDir", trashDir.Dir)
+	index, err := xdg.LoadInfoIndex(trashDir.Dir)
+	if err != nil {
+		slog.Warn("failed to read trashinfo index, it will be recreated", "path", xdg.InfoIndexPath(trashDir.Dir), "error", err)
+	}
 
-		// save directorysize cache
-		if dirCache != nil && dirCacheUpdated {
-			slog.Debug("saving directorysizes cache", "path", directorySizesPath, "isTruncate", b.noFilterApply)
-			// When all selections are made, the cache file is rewritten.
-			// (To delete old entries that are no longer needed.)
-			if err := dirCache.Save(trashDir.Dir, b.noFilterApply); err != nil { // if
-				slog.Warn("failed to save directorysizes cache", "path", directorySizesPath, "error", err)
-			}
+	files, orphanMeta := b.getFiles(dirents, fileEntries, trashDir, index, dirCache)
+	slog.Debug("found trashed files", "number", len(files), "trashDir", trashDir.Dir)
+
+	// save trashinfo index
+	if index.Changed() {
+		slog.Debug("saving trashinfo index", "path", xdg.InfoIndexPath(trashDir.Dir))
+		if err := index.Save(); err != nil {
+			slog.Warn("failed to save trashinfo index", "path", xdg.InfoIndexPath(trashDir.Dir), "error", err)
 		}
+	}
 
-		b.TrashDirs = append(b.TrashDirs, trashDir.Dir)
-		if len(files) > 0 {
-			// TODO: perf: run only when necessary
-			sortFiles(files, b.sortBy, b.ascend)
-			b.FilesByTrashDir[trashDir.Dir] = files
+	orphanFiles := getOrphanFiles(dirents, fileEntries, trashDir)
+	if len(orphanFiles) > 0 {
+		slog.Debug("found files without .trashinfo", "number", len(orphanFiles), "trashDir", trashDir.Dir)
+	}
+
+	// save directorysize cache
+	if cache != nil && dirCache.updated {
+		slog.Debug("saving directorysizes cache", "path", directorySizesPath, "isTruncate", b.noFilterApply)
+		// When all selections are made, the cache file is rewritten.
+		// (To delete old entries that are no longer needed.)
+		if err := cache.Save(trashDir.Dir, b.noFilterApply); err != nil { // if
+			slog.Warn("failed to save directorysizes cache", "path", directorySizesPath, "error", err)
 		}
-		b.Files = append(b.Files, files...)
 	}
 
-	if len(b.Files) == 0 {
-		return fmt.Errorf("%w: trashed files", ErrNotFound)
+	// TODO: perf: run only when necessary
+	sortFiles(files, b.sortBy, b.ascend)
+
+	return trashDirResult{
+		loaded:      true,
+		files:       files,
+		orphanMeta:  orphanMeta,
+		orphanFiles: orphanFiles,
 	}
+}
 
-	sortFiles(b.Files, b.sortBy, b.ascend)
+// Result of loading one entry in the info folder
+type loadResult int
 
-	// truncate to last n items
-	if b.limitLast > 0 {
-		if len(b.Files) > b.limitLast {
-			n := len(b.Files)
-			b.Files = b.Files[n-b.limitLast : n]
+const (
+	loadSkipped    loadResult = iota // not .trashinfo, failed to load or filtered out
+	loadOrphanMeta                   // .trashinfo without the file
+	loadFile
+)
+
+// Directory size cache shared by workers
+type syncDirCache struct {
+	mu      sync.Mutex
+	cache   xdg.DirCache // nil if sizes are not calculated
+	updated bool         // true if the cache expires or an entry is added
+}
+
+// Returns files and invalid metadata in the order of dirents
+// .trashinfo files are loaded and the sizes are calculated concurrently.
+func (b *Box) getFiles(dirents []fs.DirEntry, fileEntries map[string]bool, trashDir xdg.TrashDir, index *xdg.InfoIndex, dirCache *syncDirCache) (files []File, orphanMeta []File) {
+	loaded := make([]File, len(dirents))
+	results := make([]loadResult, len(dirents))
+
+	parallel.Do(len(dirents), parallel.Workers(), func(i int) {
+		loaded[i], results[i] = b.loadFile(dirents[i], fileEntries, trashDir, index, dirCache)
+	})
+
+	// merge in order to be deterministic
+	for i, r := range results {
+		switch r {
+		case loadFile:
+			files = append(files, b.expandSubPaths(loaded[i])...)
+		case loadOrphanMeta:
+			orphanMeta = append(orphanMeta, loaded[i])
 		}
 	}
 
-	return nil
+	return files, orphanMeta
 }
 
-func (b *Box) getFiles(dirents []fs.DirEntry, fileEntries map[string]bool, trashDir xdg.TrashDir, dirCache xdg.DirCache, dirCacheUpdated *bool) []File {
-	var files []File
-	for _, ent := range dirents {
-		if ent.Type().IsRegular() && strings.HasSuffix(ent.Name(), ".trashinfo") {
-			trashInfoPath := filepath.Join(trashDir.InfoDir(), ent.Name())
+// Load a .trashinfo and the corresponding file, then apply filters
+// Must be safe to call concurrently, do not modify Box.
+func (b *Box) loadFile(ent fs.DirEntry, fileEntries map[string]bool, trashDir xdg.TrashDir, index *xdg.InfoIndex, dirCache *syncDirCache) (File, loadResult) {
+	if !ent.Type().IsRegular() || !strings.HasSuffix(ent.Name(), ".trashinfo") {
+		return File{}, loadSkipped
+	}
 
-			if strings.HasPrefix(ent.Name(), "._") {
-				// exclude mac resource fork
-				slog.Debug("skipped mac resource fork of .trashinfo", "path", trashInfoPath)
-				continue
-			}
+	trashInfoPath := filepath.Join(trashDir.InfoDir(), ent.Name())
 
-			f, err := os.Open(trashInfoPath)
-			if err != nil {
-				slog.Warn("failed to open .trashinfo, skipped", "path", trashInfoPath, "error", err)
-				continue
-			}
+	if strings.HasPrefix(ent.Name(), "._") {
+		// exclude mac resource fork
+		slog.Debug("skipped mac resource fork of .trashinfo", "path", trashInfoPath)
+		return File{}, loadSkipped
+	}
 
-			info, err := xdg.NewInfo(f)
+	// Use the index if .trashinfo is not changed since the last run
+	infoStat, statErr := ent.Info()
+	var (
+		info xdg.Info
+		hit  bool
+	)
+	if statErr == nil {
+		info, hit = index.Get(ent.Name(), infoStat)
+	}
 
-			// It is better to close each time from a performance standpoint.
-			f.Close()
+	if !hit {
+		f, err := os.Open(trashInfoPath)
+		if err != nil {
+			slog.Warn("failed to open .trashinfo, skipped", "path", trashInfoPath, "error", err)
+			return File{}, loadSkipped
+		}
 
-			if err != nil {
-				slog.Warn("failed to parse .trashinfo, skipped", "path", trashInfoPath, "error", err)
-				continue
-			}
+		info, err = xdg.NewInfo(f)
 
-			if !strings.HasPrefix(info.Path, string(os.PathSeparator)) {
-				// If it was a relative path, convert it to an absolute path
-				info.Path = filepath.Join(trashDir.Root, info.Path)
-			}
+		// It is better to close each time from a performance standpoint.
+		f.Close()
 
-			trashFileName := strings.TrimSuffix(ent.Name(), ".trashinfo")
+		if err != nil {
+			slog.Warn("failed to parse .trashinfo, skipped", "path", trashInfoPath, "error", err)
+			return File{}, loadSkipped
+		}
 
-			file := File{
-				Name:          filepath.Base(info.Path),
-				OriginalPath:  info.Path,
-				TrashPath:     filepath.Join(trashDir.FilesDir(), trashFileName),
-				TrashInfoPath: trashInfoPath,
-				DeletedAt:     info.DeletionDate,
-				IsDir:         fileEntries[trashFileName],
-			}
+		if statErr == nil {
+			index.Put(ent.Name(), infoStat, info)
+		}
+	}
 
-			// If the corresponding trashed file does not exist, it is assumed to be invalid metadata and skipped
-			if _, ok := fileEntries[trashFileName]; !ok {
-				slog.Debug("file in the meta information does not exist, skipped", "trashInfoPath", file.TrashInfoPath, "trashPath", file.TrashPath)
-				b.OrphanMeta = append(b.OrphanMeta, file)
-				continue
-			}
+	if !strings.HasPrefix(info.Path, string(os.PathSeparator)) {
+		// If it was a relative path, convert it to an absolute path
+		info.Path = filepath.Join(trashDir.Root, info.Path)
+	}
+
+	trashFileName := strings.TrimSuffix(ent.Name(), ".trashinfo")
+	session, _ := info.Get(xdg.KeySession)
+
+	file := File{
+		Name:          filepath.Base(info.Path),
+		OriginalPath:  info.Path,
+		TrashPath:     filepath.Join(trashDir.FilesDir(), trashFileName),
+		TrashInfoPath: trashInfoPath,
+		TrashDir:      trashDir.Dir,
+		DeletedAt:     info.DeletionDate,
+		IsDir:         fileEntries[trashFileName],
+		Session:       session,
+	}
+
+	// If the corresponding trashed file does not exist, it is assumed to be invalid metadata and skipped
+	if _, ok := fileEntries[trashFileName]; !ok {
+		slog.Debug("file in the meta information does not exist, skipped", "trashInfoPath", file.TrashInfoPath, "trashPath", file.TrashPath)
+		return file, loadOrphanMeta
+	}
 
-			// filter by directory
-			if b.directory != "" {
-				subpath, _ := posix.CheckSubPath(b.directory, file.OriginalPath)
-				if !subpath {
-					continue
+	// filter by directory
+	if b.directory != "" {
+		subpath, _ := posix.CheckSubPath(b.directory, file.OriginalPath)
+		if !subpath {
+			return File{}, loadSkipped
+		}
+	}
+
+	// filter by original path
+	if len(b.queries) > 0 {
+		switch b.queryModeBy {
+		case ModeByFull:
+			if !slices.Contains(b.queries, file.OriginalPath) {
+				// paths inside the directory are expanded in getFiles
+				if !b.subPath || !file.IsDir || !b.hasSubPathQuery(file.OriginalPath) {
+					return File{}, loadSkipped
 				}
 			}
-
-			// filter by original path
-			if len(b.queries) > 0 {
-				switch b.queryModeBy {
-				case ModeByFull:
-					if !slices.Contains(b.queries, file.OriginalPath) {
-						continue
-					}
-				case ModeByLiteral:
-					var match bool
-					for _, q := range b.queries {
-						if strings.Contains(file.OriginalPath, q) {
-							match = true
-							break
-						}
-					}
-					if !match {
-						continue
-					}
-				case ModeByRegex:
-					var match bool
-					for _, reg := range b.queriesReg {
-						if reg.MatchString(file.OriginalPath) {
-							match = true
-							break
-						}
-					}
-					if !match {
-						continue
-					}
-				case ModeByGlob:
-					var match bool
-					for _, glob := range b.queriesGlob {
-						if glob.Match(file.OriginalPath) {
-							match = true
-							break
-						}
-					}
-					if !match {
-						continue
-					}
+		case ModeByLiteral:
+			var match bool
+			for _, q := range b.queries {
+				if strings.Contains(file.OriginalPath, q) {
+					match = true
+					break
 				}
 			}
-
-			// filter by deletedAt
-			if b.day > 0 {
-				if b.newer {
-					if b.dayPoint.After(info.DeletionDate) {
-						continue
-					}
-				} else {
-					if b.dayPoint.Before(info.DeletionDate) {
-						continue
-					}
+			if !match {
+				return File{}, loadSkipped
+			}
+		case ModeByRegex:
+			var match bool
+			for _, reg := range b.queriesReg {
+				if reg.MatchString(file.OriginalPath) {
+					match = true
+					break
 				}
 			}
-
-			// calculate file or directory size
-			if b.GetSize {
-				fi, err := os.Lstat(file.TrashPath)
-				if err != nil {
-					slog.Warn("cannot lstat(2) to the trashed file for getting size", "trashPath", file.TrashPath, "error", err)
-					goto BREAK_GET_SIZE
+			if !match {
+				return File{}, loadSkipped
+			}
+		case ModeByGlob:
+			var match bool
+			for _, glob := range b.queriesGlob {
+				if glob.Match(file.OriginalPath) {
+					match = true
+					break
 				}
+			}
+			if !match {
+				return File{}, loadSkipped
+			}
+		}
+	}
 
-				file.Mode = fi.Mode()
-				file.IsDir = fi.IsDir()
-				if !fi.IsDir() {
-					// if regular file
+	// filter by deletedAt
+	if !b.since.IsZero() && b.since.After(info.DeletionDate) {
+		return File{}, loadSkipped
+	}
+	if !b.until.IsZero() && b.until.Before(info.DeletionDate) {
+		return File{}, loadSkipped
+	}
 
-					// Files can be retrieved by stat.
-					s := fi.Size()
-					file.Size = &s
-					goto BREAK_GET_SIZE
-				}
+	// filter by extension
+	if len(b.exts) > 0 && !matchExt(file.Name, b.exts) {
+		return File{}, loadSkipped
+	}
 
-				// For directory, refer to cache and recursively calculate size if cache misses
+	// get mode and owner
+	var fi fs.FileInfo
+	if b.GetSize || b.getMode || b.needLstat() {
+		var err error
+		fi, err = os.Lstat(file.TrashPath)
+		if err != nil {
+			slog.Warn("cannot lstat(2) to the trashed file", "trashPath", file.TrashPath, "error", err)
+			if b.needLstat() {
+				// cannot be filtered
+				return File{}, loadSkipped
+			}
+		} else {
+			file.Mode = fi.Mode()
+			file.IsDir = fi.IsDir()
+		}
+	}
 
-				// Check the update time of the trashinfo file to see if the cache has become stale
-				fi, err = os.Stat(file.TrashInfoPath)
-				if err != nil {
-					// Since the file has already been loaded, it is unlikely to reach this point
-					slog.Warn("cannot stat(2) to the trashinfo file for calculating directory size", "trashInfoPath", file.TrashInfoPath, "error", err)
-					goto BREAK_GET_SIZE
-				}
+	// filter by type and owner
+	if len(b.types) > 0 && !matchType(file.Mode, b.types) {
+		return File{}, loadSkipped
+	}
+	if b.owner != "" {
+		if uid, ok := fileOwner(fi); !ok || uid != b.ownerUid {
+			return File{}, loadSkipped
+		}
+	}
 
-				// if directory, get size recursively while referring to cache
-				var size int64
-				// check cache entry
-				if item, ok := dirCache[trashFileName]; ok && item.Item.Mtime.Unix() == fi.ModTime().Unix() {
-					// cache hit and cache is not stale
-					size = item.Item.Size
-					item.Seen = true
-				} else {
-					if item == nil {
-						slog.Debug("calculating directory size", "reason", "CACHE_NOT_HIT", "trashPath", file.TrashPath)
-					} else {
-						slog.Debug("calculating directory size", "reason", "CACHE_STALE", "trashPath", file.TrashPath)
-					}
-					*dirCacheUpdated = true
-
-					// calculate directory size
-					s, err := posix.DirSizeFallback(file.TrashPath)
-					if err != nil {
-						// Even if rename(2) succeeds, the file inside may not be readable depending on the permissions.
-						slog.Warn("cannot calculate directory size", "trashPath", file.TrashPath, "error", err)
-
-						// Delete from cache because size could not be retrieved
-						// noop when there is no cache
-						delete(dirCache, trashFileName)
-
-						goto BREAK_GET_SIZE
-					}
-					size = s
-
-					// update cache
-					if item == nil {
-						// cache not hit
-
-						// add cache entry
-						dirCache[trashFileName] = &struct {
-							Item xdg.DirCacheItem
-							Seen bool
-						}{
-							Item: xdg.DirCacheItem{
-								Size:    size,
-								Mtime:   fi.ModTime(),
-								DirName: trashFileName,
-							},
-							Seen: true,
-						}
-					} else {
-						// cache hit but stale
-
-						// update new size and mtime
-						item.Item.Size = size
-						item.Item.Mtime = fi.ModTime()
-						item.Seen = true
-					}
-				}
+	// calculate file or directory size
+	if b.GetSize {
+		if fi == nil {
+			goto BREAK_GET_SIZE
+		}
+
+		if !fi.IsDir() {
+			// if regular file
+
+			// Files can be retrieved by stat.
+			s := fi.Size()
+			file.Size = &s
+			goto BREAK_GET_SIZE
+		}
+
+		// For directory, refer to cache and recursively calculate size if cache misses
+
+		// Check the update time of the trashinfo file to see if the cache has become stale
+		fi, err := os.Stat(file.TrashInfoPath)
+		if err != nil {
+			// Since the file has already been loaded, it is unlikely to reach this point
+			slog.Warn("cannot stat(2) to the trashinfo file for calculating directory size", "trashInfoPath", file.TrashInfoPath, "error", err)
+			goto BREAK_GET_SIZE
+		}
+
+		// if directory, get size recursively while referring to cache
+		var size int64
+		// check cache entry
+		dirCache.mu.Lock()
+		item, ok := dirCache.cache[trashFileName]
+		hit := ok && item.Item.Mtime.Unix() == fi.ModTime().Unix()
+		if hit {
+			// cache hit and cache is not stale
+			size = item.Item.Size
+			item.Seen = true
+		} else {
+			dirCache.updated = true
+		}
+		dirCache.mu.Unlock()
 
-				// succeed to get folder size
-				file.Size = &size
+		if !hit {
+			if item == nil {
+				slog.Debug("calculating directory size", "reason", "CACHE_NOT_HIT", "trashPath", file.TrashPath)
+			} else {
+				slog.Debug("calculating directory size", "reason", "CACHE_STALE", "trashPath", file.TrashPath)
 			}
 
-		BREAK_GET_SIZE:
-			// filter by size
-			if b.sizeHuman != "" { // See sizeHuman to allow filtering even with 0
-				// If the size is not obtained, it is nil then skipped.
-				if file.Size == nil {
-					continue
-				}
+			// calculate directory size, without holding the lock since it takes time
+			s, err := posix.DirSizeFallback(file.TrashPath)
+			if err != nil {
+				// Even if rename(2) succeeds, the file inside may not be readable depending on the permissions.
+				slog.Warn("cannot calculate directory size", "trashPath", file.TrashPath, "error", err)
+
+				// Delete from cache because size could not be retrieved
+				// noop when there is no cache
+				dirCache.mu.Lock()
+				delete(dirCache.cache, trashFileName)
+				dirCache.mu.Unlock()
 
-				if b.sizeLarger {
-					if uint64(*file.Size) < b.size {
-						continue
-					}
-				} else {
-					if uint64(*file.Size) > b.size {
-						continue
-					}
+				goto BREAK_GET_SIZE
+			}
+			size = s
+
+			// update cache
+			dirCache.mu.Lock()
+			if item == nil {
+				// cache not hit
+
+				// add cache entry
+				dirCache.cache[trashFileName] = &struct {
+					Item xdg.DirCacheItem
+					Seen bool
+				}{
+					Item: xdg.DirCacheItem{
+						Size:    size,
+						Mtime:   fi.ModTime(),
+						DirName: trashFileName,
+					},
+					Seen: true,
 				}
+			} else {
+				// cache hit but stale
+
+				// update new size and mtime
+				item.Item.Size = size
+				item.Item.Mtime = fi.ModTime()
+				item.Seen = true
+			}
+			dirCache.mu.Unlock()
+		}
+
+		// succeed to get folder size
+		file.Size = &size
+	}
+
+BREAK_GET_SIZE:
+	// filter by size
+	if b.sizeHuman != "" { // See sizeHuman to allow filtering even with 0
+		// If the size is not obtained, it is nil then skipped.
+		if file.Size == nil {
+			return File{}, loadSkipped
+		}
+
+		if b.sizeLarger {
+			if uint64(*file.Size) < b.size {
+				return File{}, loadSkipped
+			}
+		} else {
+			if uint64(*file.Size) > b.size {
+				return File{}, loadSkipped
 			}
+		}
+	}
+
+	// filter by expression
+	if b.whereExpr != nil && !b.whereExpr.Match(&file) {
+		return File{}, loadSkipped
+	}
 
-			b.hitByPath[file.OriginalPath]++
+	return file, loadFile
+}
 
-			files = append(files, file)
+// Returns files in the files folder for which there is no .trashinfo
+// DeletedAt is set to mtime instead.
+func getOrphanFiles(infoEntries []fs.DirEntry, fileEntries map[string]bool, trashDir xdg.TrashDir) []File {
+	infoNames := make(map[string]bool, len(infoEntries))
+	for _, ent := range infoEntries {
+		if name, ok := strings.CutSuffix(ent.Name(), ".trashinfo"); ok {
+			infoNames[name] = true
 		}
 	}
 
+	var files []File
+	for name, isDir := range fileEntries {
+		if infoNames[name] {
+			continue
+		}
+
+		trashPath := filepath.Join(trashDir.FilesDir(), name)
+		fi, err := os.Lstat(trashPath)
+		if err != nil {
+			slog.Warn("cannot stat file without .trashinfo, skipped", "trashPath", trashPath, "error", err)
+			continue
+		}
+
+		files = append(files, File{
+			Name:      name,
+			TrashPath: trashPath,
+			TrashDir:  trashDir.Dir,
+			DeletedAt: fi.ModTime(),
+			IsDir:     isDir,
+			Mode:      fi.Mode(),
+		})
+	}
+
+	// OriginalPath is unknown
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.TrashPath, b.TrashPath)
+	})
+
 	return files
 }
 
@@ -662,8 +959,11 @@ type File struct {
 	OriginalPath  string    // ~/.vimrc (Info.Path)
 	TrashPath     string    // ~/.local/share/Trash/files/.vimrc
 	TrashInfoPath string    // ~/.local/share/Trash/info/.vimrc.trashinfo
+	TrashDir      string    // ~/.local/share/Trash
 	DeletedAt     time.Time // 2023-01-01T00:00:00 (Info.DeletionDate)
 	IsDir         bool
+	Session       string // X-GTrash-Session, empty if trashed by other tools
+	SubPath       string // relative path inside the trashed directory, empty if this is the trashed file itself
 	// optionals below
 	Size *int64 // nil if could not get, It may not be able to be taken due to permission violation, etc.
 	Mode fs.FileMode
@@ -673,6 +973,7 @@ type Group struct {
 	Dir         string
 	IsDirCommon bool      // Whether Dir is the same for all files
 	DeletedAt   time.Time // pick one from Files
+	Session     string    // empty if grouped by DeletedAt
 	Files       []File
 }
 
@@ -718,10 +1019,20 @@ func (f *File) pathColor(s string) string {
 func (b *Box) ToGroups() []Group {
 	files := b.Files
 
-	// group by deletedAt
-	filesByDeletedAt := make(map[time.Time][]File)
+	// Group by session written by 'gtrash put', which identifies one invocation exactly.
+	// Files trashed by other tools do not have a session, so group by deletedAt instead.
+	type groupKey struct {
+		session   string
+		deletedAt time.Time
+	}
+
+	filesByKey := make(map[groupKey][]File)
 	for _, file := range files {
-		filesByDeletedAt[file.DeletedAt] = append(filesByDeletedAt[file.DeletedAt], file)
+		key := groupKey{session: file.Session}
+		if file.Session == "" {
+			key.deletedAt = file.DeletedAt
+		}
+		filesByKey[key] = append(filesByKey[key], file)
 	}
 
 	hasMultiDirs := func(files []File) bool {
@@ -738,7 +1049,7 @@ func (b *Box) ToGroups() []Group {
 	}
 
 	var groups []Group
-	for deletedAt, files := range filesByDeletedAt {
+	for key, files := range filesByKey {
 		dir := filepath.Dir(files[0].OriginalPath)
 		isDirCommon := true
 
@@ -748,13 +1059,18 @@ func (b *Box) ToGroups() []Group {
 		}
 		groups = append(groups, Group{
 			Dir:         dir,
-			DeletedAt:   deletedAt,
+			DeletedAt:   files[0].DeletedAt,
+			Session:     key.session,
 			Files:       files,
 			IsDirCommon: isDirCommon,
 		})
 	}
 
 	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].DeletedAt.Equal(groups[j].DeletedAt) {
+			// make the order stable among groups deleted at the same second
+			return groups[i].Session > groups[j].Session
+		}
 		return groups[i].DeletedAt.After(groups[j].DeletedAt)
 	})
 
@@ -762,6 +1078,19 @@ func (b *Box) ToGroups() []Group {
 }
 
 func (f *File) Delete() error {
+	if f.SubPath != "" {
+		// The rest of the directory is still in the trash can, so keep .trashinfo.
+		return f.Touch()
+	}
+
 	slog.Debug("removing .trashinfo", "trashInfoPath", f.TrashInfoPath)
 	return os.Remove(f.TrashInfoPath)
 }
+
+// Update mtime of .trashinfo to invalidate the directory size cache,
+// used when a part of the trashed directory is restored.
+func (f *File) Touch() error {
+	slog.Debug("touching .trashinfo of the trashed directory", "trashInfoPath", f.TrashInfoPath, "subPath", f.SubPath)
+	now := time.Now()
+	return os.Chtimes(f.TrashInfoPath, now, now)
+}
@@ -0,0 +1,122 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/xdg"
+)
+
+func TestToGroups(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+
+	box := NewBox()
+	box.Files = []File{
+		// same second but different put invocation
+		{OriginalPath: "/foo/a", DeletedAt: date, Session: "s1"},
+		{OriginalPath: "/foo/b", DeletedAt: date, Session: "s1"},
+		{OriginalPath: "/bar/c", DeletedAt: date, Session: "s2"},
+		// trashed by other tools
+		{OriginalPath: "/baz/d", DeletedAt: date},
+		{OriginalPath: "/baz/e", DeletedAt: date},
+		{OriginalPath: "/baz/f", DeletedAt: date.Add(-time.Hour)},
+	}
+
+	groups := box.ToGroups()
+	require.Len(t, groups, 4)
+
+	assert.Equal(t, "s2", groups[0].Session)
+	assert.Equal(t, "/bar", groups[0].Dir)
+	assert.Len(t, groups[0].Files, 1)
+
+	assert.Equal(t, "s1", groups[1].Session)
+	assert.Equal(t, "/foo", groups[1].Dir)
+	assert.Len(t, groups[1].Files, 2)
+
+	assert.Equal(t, "", groups[2].Session)
+	assert.Len(t, groups[2].Files, 2)
+
+	assert.Equal(t, date.Add(-time.Hour), groups[3].DeletedAt)
+}
+
+func TestGetOrphanFiles(t *testing.T) {
+	trashDir := xdg.NewTrashDirManual(t.TempDir())
+	require.NoError(t, trashDir.CreateDir())
+
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, os.WriteFile(filepath.Join(trashDir.FilesDir(), name), nil, 0o600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(trashDir.FilesDir(), "c"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(trashDir.InfoDir(), "a.trashinfo"), nil, 0o600))
+
+	infoEntries, err := os.ReadDir(trashDir.InfoDir())
+	require.NoError(t, err)
+	fileEntries := map[string]bool{"a": false, "b": false, "c": true}
+
+	got := getOrphanFiles(infoEntries, fileEntries, trashDir)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "b", got[0].Name)
+	assert.Equal(t, filepath.Join(trashDir.FilesDir(), "b"), got[0].TrashPath)
+	assert.Equal(t, trashDir.Dir, got[0].TrashDir)
+	assert.Empty(t, got[0].OriginalPath)
+	assert.False(t, got[0].IsDir)
+	assert.False(t, got[0].DeletedAt.IsZero())
+
+	assert.Equal(t, "c", got[1].Name)
+	assert.True(t, got[1].IsDir)
+}
+
+func TestGetFiles(t *testing.T) {
+	trashDir := xdg.NewTrashDirManual(t.TempDir())
+	require.NoError(t, trashDir.CreateDir())
+
+	writeInfo := func(name string) {
+		info := fmt.Sprintf("[Trash Info]\nPath=/tmp/%s\nDeletionDate=2024-01-01T00:00:00\n", name)
+		require.NoError(t, os.WriteFile(filepath.Join(trashDir.InfoDir(), name+".trashinfo"), []byte(info), 0o600))
+	}
+
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("file%03d", i)
+		require.NoError(t, os.WriteFile(filepath.Join(trashDir.FilesDir(), name), []byte("abc"), 0o600))
+		writeInfo(name)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(trashDir.FilesDir(), "dir"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(trashDir.FilesDir(), "dir", "a"), []byte("abcde"), 0o600))
+	writeInfo("dir")
+	writeInfo("orphan")
+
+	fileDirents, err := os.ReadDir(trashDir.FilesDir())
+	require.NoError(t, err)
+	fileEntries := make(map[string]bool)
+	for _, ent := range fileDirents {
+		fileEntries[ent.Name()] = ent.IsDir()
+	}
+	infoDirents, err := os.ReadDir(trashDir.InfoDir())
+	require.NoError(t, err)
+
+	b := NewBox(WithGetSize(true))
+	dirCache := &syncDirCache{cache: make(xdg.DirCache)}
+	files, orphanMeta := b.getFiles(infoDirents, fileEntries, trashDir, xdg.NewInfoIndex(trashDir.Dir), dirCache)
+
+	// same order as the info folder regardless of concurrency
+	require.Len(t, files, 101)
+	assert.Equal(t, "dir", files[0].Name)
+	require.NotNil(t, files[0].Size)
+	assert.Positive(t, *files[0].Size)
+	for i, f := range files[1:] {
+		assert.Equal(t, fmt.Sprintf("file%03d", i), f.Name)
+		assert.Equal(t, int64(3), *f.Size)
+	}
+
+	require.Len(t, orphanMeta, 1)
+	assert.Equal(t, "orphan", orphanMeta[0].Name)
+
+	assert.True(t, dirCache.updated)
+	assert.Contains(t, dirCache.cache, "dir")
+}
@@ -0,0 +1,314 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/umlx5h/gtrash/internal/posix"
+	"github.com/umlx5h/gtrash/internal/trash"
+	"github.com/umlx5h/gtrash/internal/tui/table"
+)
+
+const (
+	browseSizeWidth = 8
+
+	sizeUnknown = -1 // cannot be read
+	sizePending = -2 // directory size being calculated
+)
+
+// View to navigate the contents of a trashed directory,
+// used to check what is inside and to restore only a part of it.
+type browseModel struct {
+	dir trash.File // trashed directory
+
+	cwd     string           // relative path of the directory being listed, empty at the top
+	entries []browseEntry    // entries in cwd
+	err     error            // failed to read cwd
+	cursors map[string]int   // cursor position of each directory, restored when going back
+	sizes   map[string]int64 // directory sizes, key: relative path
+	cancel  context.CancelFunc
+
+	t       table.Model
+	noWidth int
+	status  string // message shown after adding a file
+}
+
+type browseEntry struct {
+	name  string
+	isDir bool
+	size  int64 // sizeUnknown or sizePending if not available
+}
+
+// Size of a directory calculated in the background
+type browseSizeMsg struct {
+	trashPath string // trashed directory being browsed
+	rel       string
+	size      int64
+}
+
+// Returns the command to calculate directory sizes
+func newBrowseModel(dir trash.File, width, height int) (*browseModel, tea.Cmd) {
+	b := &browseModel{
+		dir:     dir,
+		cursors: make(map[string]int),
+		sizes:   make(map[string]int64),
+		noWidth: 2,
+		t: table.New(
+			table.WithFocused(true),
+			table.WithStyles(focusRowStyle),
+		),
+	}
+	// columns must be set before rows
+	b.updateScreenSize(width, height)
+	cmd := b.chdir("")
+	b.updateScreenSize(width, height)
+
+	return b, cmd
+}
+
+// List the entries of the directory, rel is relative to the trashed directory
+// Directory sizes are calculated by the returned command not to block the UI on large trees.
+func (b *browseModel) chdir(rel string) tea.Cmd {
+	// remember the cursor to restore it when going back
+	b.cursors[b.cwd] = b.t.Cursor()
+
+	// sizes of the previous directory are no longer needed
+	b.close()
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+
+	b.cwd = rel
+	b.entries = nil
+	b.status = ""
+
+	dirents, err := os.ReadDir(filepath.Join(b.dir.TrashPath, rel))
+	b.err = err
+
+	var (
+		dirs, files []browseEntry
+		cmds        []tea.Cmd
+	)
+	for _, d := range dirents {
+		e := browseEntry{name: d.Name(), isDir: d.IsDir(), size: sizeUnknown}
+		if e.isDir {
+			p := filepath.Join(rel, e.name)
+			if s, ok := b.sizes[p]; ok {
+				e.size = s
+			} else {
+				e.size = sizePending
+				cmds = append(cmds, b.dirSize(ctx, p))
+			}
+			dirs = append(dirs, e)
+		} else {
+			if fi, err := d.Info(); err == nil {
+				e.size = fi.Size()
+			}
+			files = append(files, e)
+		}
+	}
+	// directories first
+	b.entries = append(dirs, files...)
+
+	b.noWidth = max(len(strconv.Itoa(len(b.entries))), 2)
+	b.setRows()
+	b.t.SetCursor(b.cursors[rel])
+
+	if len(cmds) == 0 {
+		return nil
+	}
+	// one at a time, not to walk many directories at once
+	return tea.Sequence(cmds...)
+}
+
+func (b *browseModel) setRows() {
+	rows := make([]table.Row, len(b.entries))
+	for i, e := range b.entries {
+		var size string
+		switch e.size {
+		case sizeUnknown:
+			size = "-"
+		case sizePending:
+			size = "..."
+		default:
+			size = humanize.Bytes(uint64(e.size))
+		}
+		name := e.name
+		if e.isDir {
+			name += string(filepath.Separator)
+		}
+		rows[i] = table.Row{strconv.Itoa(i + 1), size, name}
+	}
+	b.t.SetRows(rows)
+}
+
+// Command to calculate the size of the directory inside the trashed directory
+// Skipped if the directory is no longer listed.
+func (b *browseModel) dirSize(ctx context.Context, rel string) tea.Cmd {
+	trashPath := b.dir.TrashPath
+	return func() tea.Msg {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s, err := posix.DirSizeFallback(filepath.Join(trashPath, rel))
+		if err != nil {
+			s = sizeUnknown
+		}
+		return browseSizeMsg{trashPath: trashPath, rel: rel, size: s}
+	}
+}
+
+// Cache the calculated size and show it if listed
+func (b *browseModel) setSize(msg browseSizeMsg) {
+	if msg.trashPath != b.dir.TrashPath {
+		return
+	}
+	b.sizes[msg.rel] = msg.size
+
+	for i, e := range b.entries {
+		if e.isDir && filepath.Join(b.cwd, e.name) == msg.rel {
+			b.entries[i].size = msg.size
+			cursor := b.t.Cursor()
+			b.setRows()
+			b.t.SetCursor(cursor)
+			return
+		}
+	}
+}
+
+// Stop calculating directory sizes
+func (b *browseModel) close() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+// Selected entry and its path relative to the trashed directory
+func (b *browseModel) selected() (browseEntry, string, bool) {
+	row := b.t.SelectedRow()
+	if row == nil {
+		return browseEntry{}, "", false
+	}
+	idx, err := strconv.Atoi(row[0])
+	if err != nil {
+		panic(err)
+	}
+	e := b.entries[idx-1]
+	return e, filepath.Join(b.cwd, e.name), true
+}
+
+func (b *browseModel) updateScreenSize(width, height int) {
+	b.t.SetColumns([]table.Column{
+		{Title: "No", Width: b.noWidth},
+		{Title: "Size", Width: browseSizeWidth},
+		{Title: "Name", Width: max(width-b.noWidth-browseSizeWidth-4*2, 0)},
+	})
+	b.t.SetHeight(max(int(float64(height)*0.55)-paddingHeight, 0))
+}
+
+// Handle keys in the browse view, returns true to close the view
+func (m *multiRestoreModel) updateBrowse(msg tea.KeyMsg) (closed bool, cmd tea.Cmd) {
+	b := m.browse
+
+	switch {
+	case key.Matches(msg, m.keymap.browseBack):
+		b.close()
+		return true, nil
+	case key.Matches(msg, m.keymap.browseOpen):
+		if e, rel, ok := b.selected(); ok && e.isDir {
+			cmd = b.chdir(rel)
+			b.updateScreenSize(m.width, m.height)
+		}
+		return false, cmd
+	case key.Matches(msg, m.keymap.browseUp):
+		if b.cwd == "" {
+			b.close()
+			return true, nil
+		}
+		parent := filepath.Dir(b.cwd)
+		if parent == "." {
+			parent = ""
+		}
+		cmd = b.chdir(parent)
+		b.updateScreenSize(m.width, m.height)
+		return false, cmd
+	case key.Matches(msg, m.keymap.browseAdd):
+		_, rel, ok := b.selected()
+		if !ok {
+			return false, nil
+		}
+		sub, err := b.dir.SubFile(rel)
+		if err != nil {
+			b.status = "cannot add: " + err.Error()
+			return false, nil
+		}
+		m.addSubFile(sub)
+		b.status = "added to restore: " + sub.OriginalPath
+		return false, nil
+	case key.Matches(msg, m.keymap.togglePreview):
+		m.showPreview = !m.showPreview
+		return false, nil
+	}
+
+	b.t, cmd = b.t.Update(msg)
+	return false, cmd
+}
+
+// Add a file inside a trashed directory to the restore table
+func (m *multiRestoreModel) addSubFile(sub trash.File) {
+	idx, ok := m.subFiles[sub.TrashPath]
+	if !ok {
+		m.files = append(m.files, sub)
+		idx = len(m.files) - 1
+		m.subFiles[sub.TrashPath] = idx
+	}
+	if _, ok := m.selected[idx]; ok {
+		return
+	}
+	m.selected[idx] = struct{}{}
+
+	row := makeFileRow(idx, sub)
+	if m.restoreTable.input.Value() == "" || findMatch(sub.OriginalPath, m.restoreTable.input.Value()) {
+		m.restoreTable.t.SetRows(addRow(m.restoreTable.t.Rows(), row))
+	}
+	m.updateHit()
+}
+
+func (m multiRestoreModel) viewBrowse() string {
+	b := m.browse
+
+	var body strings.Builder
+
+	body.WriteString(" Browse " + posix.AbsPathToTilde(filepath.Join(b.dir.OriginalPath, b.cwd)) + string(filepath.Separator) + "\n")
+	body.WriteString(focusBorderStyle.Render(b.t.View()))
+
+	body.WriteString("\n" + m.help.ShortHelpView([]key.Binding{
+		m.keymap.browseOpen,
+		m.keymap.browseUp,
+		m.keymap.browseAdd,
+		m.keymap.togglePreview,
+		m.keymap.browseBack,
+	}))
+
+	switch {
+	case b.err != nil:
+		body.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("cannot read directory: "+b.err.Error()))
+	case b.status != "":
+		body.WriteString("\n" + b.status)
+	}
+
+	if _, rel, ok := b.selected(); ok {
+		body.WriteString("\n" + greyStyle.Render("OriginalPath:    ") + filepath.Join(b.dir.OriginalPath, rel) + "\n")
+		if m.showPreview {
+			body.WriteString(greyStyle.Render("Preview:         ") + posix.FileHead(filepath.Join(b.dir.TrashPath, rel), m.width, m.height-m.tableHeight-paddingHeight-4))
+		}
+	}
+
+	return m.wrapStyle.Render(body.String())
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+// dir/
+//
+//	sub/
+//	    a.txt (3 bytes)
+//	b.txt (5 bytes)
+func newTestBrowseDir(t *testing.T) trash.File {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a.txt"), []byte("aaa"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("bbbbb"), 0o644))
+
+	return trash.File{Name: "dir", OriginalPath: "/home/user/dir", TrashPath: dir, IsDir: true}
+}
+
+func TestBrowseModel(t *testing.T) {
+	t.Run("list directories first with sizes pending", func(t *testing.T) {
+		b, cmd := newBrowseModel(newTestBrowseDir(t), 120, 40)
+		defer b.close()
+
+		require.NoError(t, b.err)
+		assert.Equal(t, []browseEntry{
+			{name: "sub", isDir: true, size: sizePending},
+			{name: "b.txt", size: 5},
+		}, b.entries)
+		assert.NotNil(t, cmd, "directory sizes are calculated in the background")
+		assert.Equal(t, "...", b.t.Rows()[0][1])
+		assert.Equal(t, "sub/", b.t.Rows()[0][2])
+	})
+
+	t.Run("set size", func(t *testing.T) {
+		b, _ := newBrowseModel(newTestBrowseDir(t), 120, 40)
+		defer b.close()
+		b.t.SetCursor(1)
+
+		msg, ok := b.dirSize(context.Background(), "sub")().(browseSizeMsg)
+		require.True(t, ok)
+		assert.Equal(t, "sub", msg.rel)
+		assert.Positive(t, msg.size)
+
+		b.setSize(msg)
+		assert.Equal(t, msg.size, b.entries[0].size)
+		assert.Equal(t, msg.size, b.sizes["sub"])
+		assert.NotEqual(t, "...", b.t.Rows()[0][1])
+		assert.Equal(t, 1, b.t.Cursor(), "cursor is kept")
+
+		// of another trashed directory
+		b.setSize(browseSizeMsg{trashPath: "/other", rel: "sub", size: 1})
+		assert.Equal(t, msg.size, b.entries[0].size)
+	})
+
+	t.Run("cancelled after leaving the directory", func(t *testing.T) {
+		b, _ := newBrowseModel(newTestBrowseDir(t), 120, 40)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Nil(t, b.dirSize(ctx, "sub")())
+		b.close()
+	})
+
+	t.Run("chdir and back", func(t *testing.T) {
+		b, _ := newBrowseModel(newTestBrowseDir(t), 120, 40)
+		defer b.close()
+		b.sizes["sub"] = 3
+
+		e, rel, ok := b.selected()
+		require.True(t, ok)
+		assert.True(t, e.isDir)
+		assert.Equal(t, "sub", rel)
+
+		cmd := b.chdir(rel)
+		assert.Nil(t, cmd, "no directories to calculate")
+		assert.Equal(t, "sub", b.cwd)
+		assert.Equal(t, []browseEntry{{name: "a.txt", size: 3}}, b.entries)
+
+		_, rel, ok = b.selected()
+		require.True(t, ok)
+		assert.Equal(t, filepath.Join("sub", "a.txt"), rel)
+
+		// cached size is used without calculating again
+		assert.Nil(t, b.chdir(""))
+		assert.Equal(t, int64(3), b.entries[0].size)
+	})
+
+	t.Run("cursor is restored", func(t *testing.T) {
+		b, _ := newBrowseModel(newTestBrowseDir(t), 120, 40)
+		defer b.close()
+		b.sizes["sub"] = 3
+
+		b.t.SetCursor(1)
+		b.chdir("sub")
+		assert.Equal(t, 0, b.t.Cursor())
+		b.chdir("")
+		assert.Equal(t, 1, b.t.Cursor())
+	})
+
+	t.Run("cannot read", func(t *testing.T) {
+		b, cmd := newBrowseModel(trash.File{TrashPath: filepath.Join(t.TempDir(), "nonexistent")}, 120, 40)
+		defer b.close()
+		assert.Error(t, b.err)
+		assert.Nil(t, cmd)
+		_, _, ok := b.selected()
+		assert.False(t, ok)
+	})
+}
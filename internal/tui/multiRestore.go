@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -133,6 +134,7 @@ var baseKeymap = keymap{
 type keymap struct {
 	help, quit, focus, moveRight, moveLeft, runRestore, filter                                      key.Binding
 	move, moveRightALL, moveLeftALL, filterCWD, clear, pageup, pagedown, top, bottom, togglePreview key.Binding
+	browse, browseOpen, browseUp, browseAdd, browseBack, markRemove, runRemove                      key.Binding
 }
 
 type filterTable struct {
@@ -164,7 +166,16 @@ func (t *filterTable) updateInputPrompt(cwd bool) {
 }
 
 func (m *multiRestoreModel) updateHit() {
-	m.trashTable.total = len(m.files) - len(m.selected)
+	m.trashTable.total = 0
+	for i, f := range m.files {
+		if _, ok := m.selected[i]; ok || f.SubPath != "" {
+			continue
+		}
+		if _, ok := m.removed[i]; ok {
+			continue
+		}
+		m.trashTable.total++
+	}
 	m.trashTable.hit = len(m.trashTable.t.Rows())
 
 	m.trashTable.updateInputPrompt(m.filterCWD)
@@ -233,6 +244,16 @@ type multiRestoreModel struct {
 	filterCWD bool
 	filesCWD  map[int]struct{} // Specify the indices of files when filtered by cwd
 
+	browse   *browseModel   // not nil while browsing a trashed directory
+	subFiles map[string]int // files inside trashed directories added by browse, key: trashPath, value: index of files
+
+	remove        RemoveFunc       // nil if removing is not allowed
+	marked        map[int]struct{} // the indices of files to remove
+	removed       map[int]struct{} // the indices of files removed successfully
+	confirmRemove bool             // showing the confirmation screen to remove
+	status        string           // result of removing
+	removeErrors  []string         // failures of removing
+
 	confirmed    bool         // true when confirmed by pressing Enter
 	restoreFiles []trash.File // return value
 }
@@ -245,6 +266,23 @@ func (m *multiRestoreModel) getFocusTable() (focus *filterTable, notFocus *filte
 	}
 }
 
+// Enter browses a trashed directory in the left table only when nothing is to be restored,
+// otherwise it restores.
+func (m *multiRestoreModel) canBrowse() bool {
+	if m.rightFocus || len(m.selected) > 0 || m.trashTable.t.SelectedRow() == nil {
+		return false
+	}
+	return m.files[m.trashTable.getSelectedIdx()].IsDir
+}
+
+// What Enter does now
+func (m *multiRestoreModel) enterKey() key.Binding {
+	if m.canBrowse() {
+		return m.keymap.browse
+	}
+	return m.keymap.runRestore
+}
+
 func (m *multiRestoreModel) getRestoreFiles() []trash.File {
 	if len(m.selected) == 0 {
 		return nil
@@ -268,6 +306,27 @@ func (m *multiRestoreModel) getRestoreFiles() []trash.File {
 	return files
 }
 
+// Files marked to remove are prefixed
+const removeMark = "[x] "
+
+func (m *multiRestoreModel) makeRow(idx int) table.Row {
+	r := makeFileRow(idx, m.files[idx])
+	if _, ok := m.marked[idx]; ok {
+		r[len(r)-1] = removeMark + r[len(r)-1]
+	}
+	return r
+}
+
+func (m *multiRestoreModel) getRemovedFiles() []trash.File {
+	var files []trash.File
+	for i, f := range m.files {
+		if _, ok := m.removed[i]; ok {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
 func makeFileRow(idx int, f trash.File) table.Row {
 	return []string{
 		strconv.Itoa(idx + 1),
@@ -277,7 +336,8 @@ func makeFileRow(idx int, f trash.File) table.Row {
 	}
 }
 
-func getTermSize() (width int, height int) {
+// Replaced in tests, which have no terminal
+var getTermSize = func() (width int, height int) {
 	w, h, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
 		panic(err)
@@ -381,7 +441,7 @@ func makeFilterTables(files []trash.File) (left, right filterTable, fixedWidth,
 	return left, right, fixedWidth, tableHeight
 }
 
-func newMultiRestoreModel(files []trash.File) multiRestoreModel {
+func newMultiRestoreModel(files []trash.File, remove RemoveFunc) multiRestoreModel {
 	trashTable, restoreTable, fixedWidth, tableHeight := makeFilterTables(files)
 	width, height := getTermSize()
 
@@ -424,6 +484,40 @@ func newMultiRestoreModel(files []trash.File) multiRestoreModel {
 		key.WithKeys("p"),
 		key.WithHelp("p", "toggle preview"),
 	)
+	km.browse = key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("Enter", "browse directory"),
+	)
+	km.browseOpen = key.NewBinding(
+		key.WithKeys("enter", "l", "right"),
+		key.WithHelp("Enter/l/→", "open directory"),
+	)
+	km.browseUp = key.NewBinding(
+		key.WithKeys("h", "left", "backspace"),
+		key.WithHelp("h/←", "parent directory"),
+	)
+	km.browseAdd = key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("Space", "add to restore"),
+	)
+	km.browseBack = key.NewBinding(
+		key.WithKeys("esc", "q", "ctrl+c"),
+		key.WithHelp("ESC/q", "close"),
+	)
+	km.markRemove = key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "mark to remove"),
+		key.WithDisabled(),
+	)
+	km.runRemove = key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "remove marked PERMANENTLY"),
+		key.WithDisabled(),
+	)
+	if remove != nil {
+		km.markRemove.SetEnabled(true)
+		km.runRemove.SetEnabled(true)
+	}
 
 	m := multiRestoreModel{
 		trashTable:   &trashTable,
@@ -441,7 +535,12 @@ func newMultiRestoreModel(files []trash.File) multiRestoreModel {
 		files:    files,
 		help:     h,
 		selected: make(map[int]struct{}),
+		subFiles: make(map[string]int),
 		keymap:   km,
+
+		remove:  remove,
+		marked:  make(map[int]struct{}),
+		removed: make(map[int]struct{}),
 	}
 
 	return m
@@ -484,8 +583,29 @@ func (m multiRestoreModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.browse != nil {
+			closed, cmd := m.updateBrowse(msg)
+			if closed {
+				m.browse = nil
+			}
+			return m, cmd
+		}
+
+		if m.confirmRemove {
+			m.confirmRemove = false
+			if msg.String() == "y" {
+				m.runRemove()
+			}
+			return m, nil
+		}
+
 		// when focused to table
 		if ft.t.Focused() {
+			if key.Matches(msg, m.keymap.browse) && m.canBrowse() {
+				m.browse, cmd = newBrowseModel(m.files[ft.getSelectedIdx()], m.width, m.height)
+				return m, cmd
+			}
+
 			switch {
 			case key.Matches(msg, m.keymap.focus):
 				ft.t.Blur()
@@ -531,6 +651,27 @@ func (m multiRestoreModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, m.keymap.togglePreview):
 				m.showPreview = !m.showPreview
 				return m, nil
+			case key.Matches(msg, m.keymap.markRemove):
+				// only used in left table
+				if m.rightFocus || ft.t.SelectedRow() == nil {
+					return m, nil
+				}
+				idx := ft.getSelectedIdx()
+				if _, ok := m.marked[idx]; ok {
+					delete(m.marked, idx)
+				} else {
+					m.marked[idx] = struct{}{}
+				}
+				rows := ft.t.Rows()
+				rows[ft.t.Cursor()] = m.makeRow(idx)
+				ft.t.SetRows(rows)
+				ft.t.MoveDown(1)
+				return m, nil
+			case key.Matches(msg, m.keymap.runRemove):
+				if len(m.marked) > 0 {
+					m.confirmRemove = true
+				}
+				return m, nil
 			case key.Matches(msg, m.keymap.filterCWD):
 				// only used in left table
 				if m.rightFocus {
@@ -600,10 +741,18 @@ func (m multiRestoreModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			return m, cmd
 		}
+	case browseSizeMsg:
+		if m.browse != nil {
+			m.browse.setSize(msg)
+		}
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.updateScreenSize()
+		if m.browse != nil {
+			m.browse.updateScreenSize(m.width, m.height)
+		}
 	}
 
 	// ft.t, cmd = ft.t.Update(msg)
@@ -665,13 +814,14 @@ func (m *multiRestoreModel) moveRow() {
 	idx := from.getSelectedIdx()
 	if !m.rightFocus {
 		m.selected[idx] = struct{}{}
+		delete(m.marked, idx)
 	} else {
 		delete(m.selected, idx)
 	}
 
 	// delete row from focus table
 	rows := from.t.Rows()
-	selectedRow := from.t.SelectedRow()
+	selectedRow := m.makeRow(idx)
 	cursor := from.t.Cursor()
 	if len(rows) >= 2 && len(rows) == cursor+1 {
 		// When the last line is selected, shift the focus up one line
@@ -681,6 +831,12 @@ func (m *multiRestoreModel) moveRow() {
 	rows = deleteRow(rows, cursor)
 	from.t.SetRows(rows)
 
+	// files inside trashed directories are only listed in the restore table
+	if m.rightFocus && m.files[idx].SubPath != "" {
+		m.updateHit()
+		return
+	}
+
 	// add to other side table if filter matches
 	if to.input.Value() == "" || findMatch(selectedRow[len(selectedRow)-1], to.input.Value()) {
 		rows = to.t.Rows()
@@ -705,19 +861,32 @@ func (m *multiRestoreModel) moveRowALL() {
 	}
 
 	// apply to selected
-	for _, idx := range from.getIndices() {
+	indices := from.getIndices()
+	for _, idx := range indices {
 		if !m.rightFocus {
 			m.selected[idx] = struct{}{}
+			delete(m.marked, idx)
 		} else {
 			delete(m.selected, idx)
 		}
 	}
 
 	// delete all rows from focus table
-	rows := from.t.Rows()
+	rows := make([]table.Row, len(indices))
+	for i, idx := range indices {
+		rows[i] = m.makeRow(idx)
+	}
 	from.t.SetCursor(0)
 	from.t.SetRows(nil)
 
+	// files inside trashed directories are only listed in the restore table
+	if m.rightFocus {
+		rows = slices.DeleteFunc(rows, func(r table.Row) bool {
+			idx, _ := strconv.Atoi(r[0])
+			return m.files[idx-1].SubPath != ""
+		})
+	}
+
 	// add to other side table if filter matches
 	if to.input.Value() == "" { // if filter not used, append all
 		rows = addRows(to.t.Rows(), rows)
@@ -748,7 +917,10 @@ func (m *multiRestoreModel) filterApply() {
 	for i, f := range m.files {
 		// Exclude already selected rows from filtering
 		if !m.rightFocus {
-			if _, ok := m.selected[i]; ok {
+			if _, ok := m.selected[i]; ok || f.SubPath != "" {
+				continue
+			}
+			if _, ok := m.removed[i]; ok {
 				continue
 			}
 
@@ -766,7 +938,7 @@ func (m *multiRestoreModel) filterApply() {
 		}
 
 		if ft.input.Value() == "" || findMatch(f.OriginalPath, ft.input.Value()) {
-			rows = append(rows, makeFileRow(i, f))
+			rows = append(rows, m.makeRow(i))
 		}
 	}
 
@@ -779,11 +951,21 @@ func findMatch(text, pattern string) bool {
 }
 
 func (m multiRestoreModel) View() string {
+	if m.browse != nil {
+		return m.viewBrowse()
+	}
+	if m.confirmRemove {
+		return m.viewConfirmRemove()
+	}
+
 	var body strings.Builder
 
 	body.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.trashTable.View(!m.rightFocus), m.restoreTable.View(m.rightFocus)))
 
 	if m.showHelp {
+		browse := m.keymap.browse
+		browse.SetHelp("Enter", "browse directory if nothing to restore")
+
 		help := m.help.FullHelpView([][]key.Binding{
 			{
 				m.keymap.moveRight,
@@ -798,6 +980,11 @@ func (m multiRestoreModel) View() string {
 				m.keymap.filterCWD,
 				m.keymap.clear,
 				m.keymap.togglePreview,
+				browse,
+			},
+			{
+				m.keymap.markRemove,
+				m.keymap.runRemove,
 			},
 			{
 				m.keymap.pageup,
@@ -819,11 +1006,18 @@ func (m multiRestoreModel) View() string {
 			m.keymap.focus,
 			m.keymap.moveRight,
 			m.keymap.moveLeft,
-			m.keymap.runRestore,
+			m.enterKey(),
 			m.keymap.filter,
 		})
 		body.WriteString("\n" + help)
 
+		if m.status != "" {
+			body.WriteString("\n" + m.status)
+			for _, e := range m.removeErrors {
+				body.WriteString("\n" + errorStyle.Render(e))
+			}
+		}
+
 		body.WriteString("\n" + m.viewMetadata())
 	}
 
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+func TestMain(m *testing.M) {
+	getTermSize = func() (int, int) {
+		return 120, 40
+	}
+	m.Run()
+}
+
+func testFiles(dir string) []trash.File {
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []trash.File{
+		{Name: "dir", OriginalPath: "/home/user/dir", TrashPath: dir, IsDir: true, DeletedAt: deletedAt},
+		{Name: "file", OriginalPath: "/home/user/file", TrashPath: "/nonexistent/file", DeletedAt: deletedAt},
+	}
+}
+
+func update(t *testing.T, m multiRestoreModel, keys ...tea.KeyMsg) (multiRestoreModel, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var model tea.Model
+		model, cmd = m.Update(k)
+		m = model.(multiRestoreModel)
+	}
+	return m, cmd
+}
+
+var (
+	keyEnter = tea.KeyMsg{Type: tea.KeyEnter}
+	keyRight = tea.KeyMsg{Type: tea.KeyRight}
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+	keyTab   = tea.KeyMsg{Type: tea.KeyTab}
+	keyEsc   = tea.KeyMsg{Type: tea.KeyEsc}
+)
+
+func keyRunes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestMultiRestoreEnter(t *testing.T) {
+	t.Run("browse a directory if nothing to restore", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+		assert.True(t, m.canBrowse())
+		assert.Equal(t, m.keymap.browse, m.enterKey())
+
+		m, _ = update(t, m, keyEnter)
+		require.NotNil(t, m.browse)
+		assert.False(t, m.confirmed)
+
+		// closed
+		m, _ = update(t, m, keyEsc)
+		assert.Nil(t, m.browse)
+	})
+
+	t.Run("do nothing on a file if nothing to restore", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+		m, _ = update(t, m, keyDown)
+		assert.False(t, m.canBrowse())
+
+		m, cmd := update(t, m, keyEnter)
+		assert.Nil(t, m.browse)
+		assert.False(t, m.confirmed)
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("right moves a directory", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+		m, _ = update(t, m, keyRight)
+		assert.Nil(t, m.browse)
+		assert.Contains(t, m.selected, 0)
+		assert.Len(t, m.restoreTable.t.Rows(), 1)
+	})
+
+	t.Run("restore if files are selected", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+		// move the file, the cursor stays on the directory
+		m, _ = update(t, m, keyDown, keyRunes("l"))
+		require.Equal(t, "1", m.trashTable.t.SelectedRow()[0])
+		assert.False(t, m.canBrowse())
+		assert.Equal(t, m.keymap.runRestore, m.enterKey())
+
+		m, cmd := update(t, m, keyEnter)
+		assert.Nil(t, m.browse)
+		assert.True(t, m.confirmed)
+		require.Len(t, m.restoreFiles, 1)
+		assert.Equal(t, "/home/user/file", m.restoreFiles[0].OriginalPath)
+		assert.NotNil(t, cmd)
+	})
+
+	t.Run("not in the right table", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+		m, _ = update(t, m, keyTab)
+		assert.False(t, m.canBrowse())
+	})
+}
+
+func TestAddSubFile(t *testing.T) {
+	m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+
+	sub := trash.File{
+		Name:         "a.txt",
+		OriginalPath: "/home/user/dir/a.txt",
+		TrashPath:    "/trash/files/dir/a.txt",
+		SubPath:      "a.txt",
+	}
+
+	m.addSubFile(sub)
+	m.addSubFile(sub)
+
+	assert.Len(t, m.files, 3, "added only once")
+	assert.Equal(t, map[string]int{sub.TrashPath: 2}, m.subFiles)
+	assert.Contains(t, m.selected, 2)
+	assert.Len(t, m.restoreTable.t.Rows(), 1)
+	assert.Equal(t, 1, m.restoreTable.total)
+	// never listed in the left table
+	assert.Equal(t, 2, m.trashTable.total)
+
+	// moved back, then added again
+	m, _ = update(t, m, keyTab, keyRunes("h"))
+	assert.NotContains(t, m.selected, 2)
+	assert.Empty(t, m.restoreTable.t.Rows())
+	assert.Len(t, m.trashTable.t.Rows(), 2)
+
+	m.addSubFile(sub)
+	assert.Len(t, m.files, 3, "index is reused")
+	assert.Contains(t, m.selected, 2)
+	assert.Len(t, m.restoreTable.t.Rows(), 1)
+}
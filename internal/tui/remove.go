@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/exp/maps"
+)
+
+var errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+// Remove the marked files, failures are kept marked and shown under the tables
+func (m *multiRestoreModel) runRemove() {
+	indices := maps.Keys(m.marked)
+	slices.Sort(indices)
+
+	m.removeErrors = nil
+	var removed int
+	for _, idx := range indices {
+		f := m.files[idx]
+		if err := m.remove(f); err != nil {
+			m.removeErrors = append(m.removeErrors, fmt.Sprintf("cannot remove %q: %s", f.OriginalPath, err))
+			continue
+		}
+		removed++
+		delete(m.marked, idx)
+		m.removed[idx] = struct{}{}
+	}
+
+	m.status = fmt.Sprintf("Removed %d/%d trashed files", removed, len(indices))
+	if len(m.removeErrors) > 0 {
+		m.status += fmt.Sprintf(", following %d files could not be removed", len(m.removeErrors))
+	}
+
+	// rows are always removed from the left table
+	if m.rightFocus {
+		m.trashTable.t.Focus()
+		m.trashTable.t.SetStyles(focusRowStyle)
+		m.restoreTable.t.Blur()
+		m.restoreTable.t.SetStyles(notFocusRowStyle)
+		m.rightFocus = false
+	}
+	m.filterApply()
+}
+
+func (m multiRestoreModel) viewConfirmRemove() string {
+	indices := maps.Keys(m.marked)
+	slices.Sort(indices)
+
+	var body strings.Builder
+
+	body.WriteString(errorStyle.Render(fmt.Sprintf("Following %d trashed files will be removed PERMANENTLY", len(indices))) + "\n\n")
+
+	// leave space for the prompt
+	limit := max(m.height-6, 1)
+	for i, idx := range indices {
+		if i == limit {
+			body.WriteString(greyStyle.Render(fmt.Sprintf("  ... and %d more", len(indices)-limit)) + "\n")
+			break
+		}
+		body.WriteString("  " + m.files[idx].OriginalPathFormat(true, true) + "\n")
+	}
+
+	body.WriteString("\nAre you sure you want to remove PERMANENTLY? (y/N) ")
+
+	return m.wrapStyle.Render(body.String())
+}
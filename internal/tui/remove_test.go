@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/trash"
+)
+
+func TestMultiRestoreRemove(t *testing.T) {
+	t.Run("disabled without remove func", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), nil)
+		m, _ = update(t, m, keyRunes("x"), keyRunes("X"))
+		assert.Empty(t, m.marked)
+		assert.False(t, m.confirmRemove)
+	})
+
+	t.Run("mark and confirm", func(t *testing.T) {
+		var removed []string
+		m := newMultiRestoreModel(testFiles(t.TempDir()), func(f trash.File) error {
+			removed = append(removed, f.Name)
+			return nil
+		})
+
+		// X does nothing if nothing is marked
+		m, _ = update(t, m, keyRunes("X"))
+		assert.False(t, m.confirmRemove)
+
+		// x marks and moves down
+		m, _ = update(t, m, keyRunes("x"))
+		assert.Equal(t, map[int]struct{}{0: {}}, m.marked)
+		assert.Equal(t, removeMark+"/home/user/dir", m.trashTable.t.Rows()[0][2])
+		assert.Equal(t, 1, m.trashTable.t.Cursor())
+
+		// x again unmarks
+		m, _ = update(t, m, keyRunes("k"), keyRunes("x"))
+		assert.Empty(t, m.marked)
+		assert.Equal(t, "/home/user/dir", m.trashTable.t.Rows()[0][2])
+
+		m, _ = update(t, m, keyRunes("k"), keyRunes("x"))
+		assert.Equal(t, map[int]struct{}{0: {}}, m.marked)
+
+		// anything other than y cancels
+		m, _ = update(t, m, keyRunes("X"))
+		assert.True(t, m.confirmRemove)
+		assert.Contains(t, m.View(), "Following 1 trashed files will be removed PERMANENTLY")
+		m, _ = update(t, m, keyRunes("n"))
+		assert.False(t, m.confirmRemove)
+		assert.Empty(t, removed)
+
+		m, _ = update(t, m, keyRunes("X"), keyRunes("y"))
+		assert.False(t, m.confirmRemove)
+		assert.Equal(t, []string{"dir"}, removed)
+		assert.Empty(t, m.marked)
+		assert.Equal(t, map[int]struct{}{0: {}}, m.removed)
+		assert.Equal(t, "Removed 1/1 trashed files", m.status)
+
+		// row is gone from the left table
+		require.Len(t, m.trashTable.t.Rows(), 1)
+		assert.Equal(t, "2", m.trashTable.t.Rows()[0][0])
+		assert.Equal(t, 1, m.trashTable.total)
+		assert.Equal(t, "dir", m.getRemovedFiles()[0].Name)
+	})
+
+	t.Run("failed files are kept", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), func(f trash.File) error {
+			if f.Name == "file" {
+				return errors.New("permission denied")
+			}
+			return nil
+		})
+
+		m, _ = update(t, m, keyRunes("x"), keyRunes("x"), keyRunes("X"), keyRunes("y"))
+		assert.Equal(t, map[int]struct{}{1: {}}, m.marked, "still marked")
+		assert.Equal(t, map[int]struct{}{0: {}}, m.removed)
+		assert.Equal(t, "Removed 1/2 trashed files, following 1 files could not be removed", m.status)
+		assert.Equal(t, []string{`cannot remove "/home/user/file": permission denied`}, m.removeErrors)
+
+		require.Len(t, m.trashTable.t.Rows(), 1)
+		assert.Equal(t, removeMark+"/home/user/file", m.trashTable.t.Rows()[0][2])
+	})
+
+	t.Run("moving to restore unmarks", func(t *testing.T) {
+		m := newMultiRestoreModel(testFiles(t.TempDir()), func(trash.File) error { return nil })
+		m, _ = update(t, m, keyRunes("x"), keyRunes("k"), keyRunes("l"))
+		assert.Empty(t, m.marked)
+		assert.Contains(t, m.selected, 0)
+
+		// only in the left table
+		m, _ = update(t, m, keyTab, keyRunes("x"))
+		assert.Empty(t, m.marked)
+	})
+}
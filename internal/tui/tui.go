@@ -10,8 +10,15 @@ import (
 	"github.com/umlx5h/gtrash/internal/trash"
 )
 
-func FilesSelect(files []trash.File) ([]trash.File, error) {
-	m := newMultiRestoreModel(files)
+var ErrNoSelected = errors.New("no selected")
+
+// Remove a trashed file permanently
+type RemoveFunc func(trash.File) error
+
+// Select files to restore.
+// If remove is not nil, files can also be removed in the TUI, and removed files are returned even if nothing is selected to restore.
+func FilesSelect(files []trash.File, remove RemoveFunc) (restore []trash.File, removed []trash.File, err error) {
+	m := newMultiRestoreModel(files, remove)
 	result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
 	if err != nil {
 		fmt.Println("Error running program:", err)
@@ -19,12 +26,13 @@ func FilesSelect(files []trash.File) ([]trash.File, error) {
 	}
 
 	if r, ok := result.(multiRestoreModel); ok {
+		removed = r.getRemovedFiles()
 		if r.confirmed {
-			return r.restoreFiles, nil
+			return r.restoreFiles, removed, nil
 		}
 	}
 
-	return nil, errors.New("no selected")
+	return nil, removed, ErrNoSelected
 }
 
 func GroupSelect(groups []trash.Group) (trash.Group, error) {
@@ -41,7 +49,7 @@ func GroupSelect(groups []trash.Group) (trash.Group, error) {
 		}
 	}
 
-	return trash.Group{}, errors.New("no selected")
+	return trash.Group{}, ErrNoSelected
 }
 
 func BoolPrompt(prompt string) bool {
@@ -0,0 +1,170 @@
+package xdg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Bump when the format of the index file changes, old index is discarded
+const infoIndexVersion = 1
+
+// Parsed .trashinfo files in one trash can, so that only changed files are read on the next run
+// Not part of the specification, stored in $XDG_CACHE_HOME/gtrash/index.
+// Safe for concurrent use.
+type InfoIndex struct {
+	Version  int                        `json:"version"`
+	TrashDir string                     `json:"trash_dir"`
+	Entries  map[string]*InfoIndexEntry `json:"entries"` // key: .trashinfo file name
+
+	mu      sync.Mutex
+	updated bool // true if an entry is added or updated
+}
+
+type InfoIndexEntry struct {
+	// An entry is valid while mtime and size of .trashinfo are not changed
+	Mtime int64 `json:"mtime"` // unix nano
+	Size  int64 `json:"size"`
+	Info  Info  `json:"info"`
+
+	seen bool
+}
+
+// $XDG_CACHE_HOME/gtrash/index/<hash of trashDir>.json
+func InfoIndexPath(trashDir string) string {
+	h := sha256.Sum256([]byte(trashDir))
+	return filepath.Join(DirAppCache, "index", hex.EncodeToString(h[:8])+".json")
+}
+
+func NewInfoIndex(trashDir string) *InfoIndex {
+	return &InfoIndex{
+		Version:  infoIndexVersion,
+		TrashDir: trashDir,
+		Entries:  make(map[string]*InfoIndexEntry),
+	}
+}
+
+// Load the index of trashDir
+// If the index does not exist or is outdated, returns an empty index without error.
+func LoadInfoIndex(trashDir string) (*InfoIndex, error) {
+	b, err := os.ReadFile(InfoIndexPath(trashDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return NewInfoIndex(trashDir), nil
+		}
+		return NewInfoIndex(trashDir), err
+	}
+
+	var x InfoIndex
+	if err := json.Unmarshal(b, &x); err != nil {
+		return NewInfoIndex(trashDir), fmt.Errorf("parse index: %w", err)
+	}
+
+	// hash collision or old format
+	if x.Version != infoIndexVersion || x.TrashDir != trashDir || x.Entries == nil {
+		return NewInfoIndex(trashDir), nil
+	}
+
+	for _, e := range x.Entries {
+		// same as NewInfo
+		e.Info.DeletionDate = e.Info.DeletionDate.Local()
+	}
+
+	return &x, nil
+}
+
+// Returns the parsed .trashinfo if it is not changed since indexed
+// fi is the result of lstat(2) to the .trashinfo.
+func (x *InfoIndex) Get(name string, fi fs.FileInfo) (Info, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	e, ok := x.Entries[name]
+	if !ok || e.Mtime != fi.ModTime().UnixNano() || e.Size != fi.Size() {
+		return Info{}, false
+	}
+	e.seen = true
+	return e.Info, true
+}
+
+// Add or update the entry
+func (x *InfoIndex) Put(name string, fi fs.FileInfo, info Info) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.Entries[name] = &InfoIndexEntry{
+		Mtime: fi.ModTime().UnixNano(),
+		Size:  fi.Size(),
+		Info:  info,
+		seen:  true,
+	}
+	x.updated = true
+}
+
+// Returns true if the index needs to be saved
+func (x *InfoIndex) Changed() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.updated {
+		return true
+	}
+	for _, e := range x.Entries {
+		if !e.seen {
+			return true
+		}
+	}
+	return false
+}
+
+// Save the index atomically, entries not seen since loaded are removed
+// (.trashinfo was removed by restore or rm)
+func (x *InfoIndex) Save() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for name, e := range x.Entries {
+		if !e.seen {
+			delete(x.Entries, name)
+		}
+	}
+
+	b, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+
+	path := InfoIndexPath(x.TrashDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file in the same directory, then rename(2)
+	// to avoid corruption by gtrash running at the same time.
+	f, err := os.CreateTemp(filepath.Dir(path), ".index_gtrash_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+
+	x.updated = false
+	return nil
+}
@@ -0,0 +1,72 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoIndex(t *testing.T) {
+	orig := DirAppCache
+	DirAppCache = t.TempDir()
+	t.Cleanup(func() { DirAppCache = orig })
+
+	infoDir := t.TempDir()
+	writeInfo := func(name string, content string) os.FileInfo {
+		path := filepath.Join(infoDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		fi, err := os.Lstat(path)
+		require.NoError(t, err)
+		return fi
+	}
+
+	date, err := time.ParseInLocation(timeFormat, "2023-01-01T00:00:00", time.Local)
+	require.NoError(t, err)
+	infoA := Info{Path: "/a", DeletionDate: date, Extra: []InfoEntry{{Key: KeySession, Value: "s1"}}}
+	infoB := Info{Path: "/b", DeletionDate: date}
+
+	fiA := writeInfo("a.trashinfo", infoA.String())
+	fiB := writeInfo("b.trashinfo", infoB.String())
+
+	x, err := LoadInfoIndex("/trash")
+	require.NoError(t, err, "not exist yet")
+	assert.False(t, x.Changed())
+
+	_, ok := x.Get("a.trashinfo", fiA)
+	assert.False(t, ok)
+	x.Put("a.trashinfo", fiA, infoA)
+	x.Put("b.trashinfo", fiB, infoB)
+	assert.True(t, x.Changed())
+	require.NoError(t, x.Save())
+
+	x, err = LoadInfoIndex("/trash")
+	require.NoError(t, err)
+	got, ok := x.Get("a.trashinfo", fiA)
+	require.True(t, ok)
+	assert.Equal(t, infoA, got)
+
+	// b.trashinfo was removed, so not seen
+	assert.True(t, x.Changed())
+	require.NoError(t, x.Save())
+
+	x, err = LoadInfoIndex("/trash")
+	require.NoError(t, err)
+	assert.NotContains(t, x.Entries, "b.trashinfo")
+
+	t.Run("changed", func(t *testing.T) {
+		infoA.Path = "/aa"
+		fi := writeInfo("a.trashinfo", infoA.String())
+		_, ok := x.Get("a.trashinfo", fi)
+		assert.False(t, ok, "size is changed")
+	})
+
+	t.Run("other_trash_dir", func(t *testing.T) {
+		x, err := LoadInfoIndex("/other")
+		require.NoError(t, err)
+		assert.Empty(t, x.Entries)
+	})
+}
@@ -15,6 +15,11 @@ var (
 	dirDataHome string
 
 	DirHomeTrash string
+
+	// $XDG_DATA_HOME/gtrash, data owned by gtrash itself (not part of the specification)
+	DirAppData string
+	// $XDG_CACHE_HOME/gtrash, can be removed at any time
+	DirAppCache string
 )
 
 func init() {
@@ -40,4 +45,14 @@ func init() {
 	} else {
 		DirHomeTrash = filepath.Join(dirDataHome, "Trash")
 	}
+
+	DirAppData = filepath.Join(dirDataHome, "gtrash")
+
+	dirCacheHome := filepath.Join(dirHome, ".cache")
+	if d, ok := os.LookupEnv("XDG_CACHE_HOME"); ok {
+		if abs, err := filepath.Abs(d); err == nil {
+			dirCacheHome = abs
+		}
+	}
+	DirAppCache = filepath.Join(dirCacheHome, "gtrash")
 }
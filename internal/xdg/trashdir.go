@@ -10,10 +10,15 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/gobwas/glob"
 	"github.com/moby/sys/mountinfo"
+	"github.com/umlx5h/gtrash/internal/config"
 	"github.com/umlx5h/gtrash/internal/env"
+	"github.com/umlx5h/gtrash/internal/parallel"
 )
 
 type trashDirType string
@@ -92,38 +97,115 @@ func ScanTrashDirs() []TrashDir {
 
 	// Get all mount points to get external trash cans
 	slog.Debug("getting all mountpoints")
-	topDirs, err := getAllMountpoints()
+	topDirs, err := getAllMountpoints(newMountFilter(env.Config.Mount))
 	if err != nil {
 		slog.Warn("failed to get all mountpoints, do not use external trash", "error", err)
 		return trashDirList
 	}
 
 	uid := strconv.Itoa(os.Getuid())
+	timeout := env.Config.Mount.StatTimeout()
 
 	// Check to see if the .Trash directory exists
-	for _, topDir := range topDirs {
-		// 2. check $topDir/.Trash/$uid
-		trashDir := filepath.Join(topDir, ".Trash", uid)
-
-		if _, err := os.Stat(trashDir); err == nil {
-			trashDirList = append(trashDirList, TrashDir{
-				Root:    topDir,
-				Dir:     trashDir,
-				dirType: trashDirTypeExternal,
-			})
-			slog.Debug("found external trash", "directory", trashDir)
-		}
+	// stat(2) may be slow on network file systems, so check mountpoints concurrently
+	found := make([][]TrashDir, len(topDirs))
+	timedOut := make([]bool, len(topDirs))
+	parallel.Do(len(topDirs), parallel.Workers(), func(i int) {
+		found[i], timedOut[i] = scanExternalTrashDirsTimeout(topDirs[i], uid, timeout)
+	})
 
-		// 3. check $topDir/Trash-$uid
-		trashDir = filepath.Join(topDir, fmt.Sprintf(".Trash-%s", uid))
-		if _, err = os.Stat(trashDir); err == nil {
-			trashDirList = append(trashDirList, TrashDir{
-				Root:    topDir,
-				Dir:     trashDir,
-				dirType: trashDirTypeExternalAlt,
-			})
-			slog.Debug("found external alternative trash", "directory", trashDir)
+	// keep the order of mountpoints
+	var skipped []string
+	for i, dirs := range found {
+		if timedOut[i] {
+			skipped = append(skipped, topDirs[i])
+			continue
 		}
+		trashDirList = append(trashDirList, dirs...)
+	}
+
+	if len(skipped) > 0 {
+		slog.Warn("skipped mountpoints not responding, they can be excluded by [mount] in the config file",
+			"timeout", timeout, "mountpoints", skipped)
+	}
+
+	return trashDirList
+}
+
+// Same as scanExternalTrashDirs, but gives up if stat(2) does not return within timeout
+// (e.g. hung NFS or sshfs).
+func scanExternalTrashDirsTimeout(topDir string, uid string, timeout time.Duration) (trashDirs []TrashDir, timedOut bool) {
+	trashDirs, ok := withTimeout(topDir, timeout, func() []TrashDir {
+		return scanExternalTrashDirs(topDir, uid)
+	})
+	if !ok {
+		slog.Debug("timed out to stat mountpoint", "mountpoint", topDir, "timeout", timeout)
+	}
+	return trashDirs, !ok
+}
+
+// Paths not responding within the timeout, not accessed again in this process
+var deadPaths sync.Map
+
+// Returns the result of fn accessing path, ok is false if it does not return within timeout.
+// A goroutine blocked in a system call cannot be stopped and is left behind,
+// so path is marked as dead and later calls return immediately to leak at most one goroutine per path.
+// 0 means no timeout.
+func withTimeout[T any](path string, timeout time.Duration, fn func() T) (result T, ok bool) {
+	if _, dead := deadPaths.Load(path); dead {
+		return result, false
+	}
+	if timeout == 0 {
+		return fn(), true
+	}
+
+	ch := make(chan T, 1)
+	go func() {
+		ch <- fn()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-ch:
+		return result, true
+	case <-timer.C:
+		deadPaths.Store(path, struct{}{})
+		return result, false
+	}
+}
+
+// Same as withTimeout with [mount] timeout, used to read trash cans on a mountpoint
+func WithMountTimeout[T any](topDir string, fn func() T) (result T, ok bool) {
+	return withTimeout(topDir, env.Config.Mount.StatTimeout(), fn)
+}
+
+// Returns external trash cans in topDir
+func scanExternalTrashDirs(topDir string, uid string) []TrashDir {
+	var trashDirList []TrashDir
+
+	// 2. check $topDir/.Trash/$uid
+	trashDir := filepath.Join(topDir, ".Trash", uid)
+
+	if _, err := osStat(trashDir); err == nil {
+		trashDirList = append(trashDirList, TrashDir{
+			Root:    topDir,
+			Dir:     trashDir,
+			dirType: trashDirTypeExternal,
+		})
+		slog.Debug("found external trash", "directory", trashDir)
+	}
+
+	// 3. check $topDir/Trash-$uid
+	trashDir = filepath.Join(topDir, fmt.Sprintf(".Trash-%s", uid))
+	if _, err := osStat(trashDir); err == nil {
+		trashDirList = append(trashDirList, TrashDir{
+			Root:    topDir,
+			Dir:     trashDir,
+			dirType: trashDirTypeExternalAlt,
+		})
+		slog.Debug("found external alternative trash", "directory", trashDir)
 	}
 
 	return trashDirList
@@ -158,15 +240,33 @@ func LookupTrashDir(path string) (home *TrashDir, external *TrashDir, err error)
 		return homeTrash, nil, nil
 	}
 
+	// the file system may be a hung network file system
+	type result struct {
+		external *TrashDir
+		err      error
+	}
+	timeout := env.Config.Mount.StatTimeout()
+	r, ok := withTimeout(filepath.Dir(path), timeout, func() result {
+		external, err := lookupExternalTrashDir(path)
+		return result{external, err}
+	})
+	if !ok {
+		return homeTrash, nil, fmt.Errorf("file system not responding within %s", timeout)
+	}
+
+	return homeTrash, r.external, r.err
+}
+
+func lookupExternalTrashDir(path string) (*TrashDir, error) {
 	// obtain a mount point associated with a file
 	topDir, err := getMountpoint(path)
 	if err != nil {
-		return homeTrash, nil, fmt.Errorf("get mountpoint: %w", err)
+		return nil, fmt.Errorf("get mountpoint: %w", err)
 	}
 
 	// 2. Check $topDir/.Trash/$uid available
 	if trashDir, err := useExternalTrash(topDir); err == nil {
-		return homeTrash, &TrashDir{
+		return &TrashDir{
 			Root:    topDir,
 			Dir:     trashDir,
 			dirType: trashDirTypeExternal,
@@ -175,13 +275,13 @@ func LookupTrashDir(path string) (home *TrashDir, external *TrashDir, err error)
 
 	// 3. Check $topDir/Trash-$uid available
 	if trashDir, err := useExternalTrashAlt(topDir); err == nil {
-		return homeTrash, &TrashDir{
+		return &TrashDir{
 			Root:    topDir,
 			Dir:     trashDir,
 			dirType: trashDirTypeExternalAlt,
 		}, nil
 	} else {
-		return homeTrash, nil, fmt.Errorf("external_trash: %w", err)
+		return nil, fmt.Errorf("external_trash: %w", err)
 	}
 }
 
@@ -202,14 +302,71 @@ var skipFSType = []string{
 	"fusectl",
 }
 
-func getAllMountpoints() ([]string, error) {
+// Filter of mountpoints set by [mount] in the config file
+type mountFilter struct {
+	includeFSTypes []string
+	excludeFSTypes []string
+	includePaths   []glob.Glob
+	excludePaths   []glob.Glob
+}
+
+func newMountFilter(c config.Mount) mountFilter {
+	compile := func(patterns []string) []glob.Glob {
+		var globs []glob.Glob
+		for _, p := range patterns {
+			// already checked when loaded
+			if g, err := glob.Compile(p, '/'); err == nil {
+				globs = append(globs, g)
+			}
+		}
+		return globs
+	}
+
+	return mountFilter{
+		includeFSTypes: c.IncludeFSTypes,
+		excludeFSTypes: c.ExcludeFSTypes,
+		includePaths:   compile(c.IncludePaths),
+		excludePaths:   compile(c.ExcludePaths),
+	}
+}
+
+func (f mountFilter) match(i *mountinfo.Info, fsTypes []string, paths []glob.Glob) bool {
+	if slices.Contains(fsTypes, i.FSType) {
+		return true
+	}
+	for _, g := range paths {
+		if g.Match(i.Mountpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f mountFilter) include(i *mountinfo.Info) bool {
+	return f.match(i, f.includeFSTypes, f.includePaths)
+}
+
+func (f mountFilter) exclude(i *mountinfo.Info) bool {
+	return f.match(i, f.excludeFSTypes, f.excludePaths)
+}
+
+func getAllMountpoints(filter mountFilter) ([]string, error) {
 	infos, err := mountinfo.GetMounts(func(i *mountinfo.Info) (skip bool, stop bool) {
+		// Read-only file systems are excluded, since files cannot be trashed.
+		if i.Options == "ro" || strings.HasPrefix(i.Options, "ro,") {
+			return true, false
+		}
+
+		if filter.include(i) {
+			return false, false
+		}
+
 		if slices.Contains(skipFSType, i.FSType) {
 			return true, false
 		}
 
-		// Read-only file systems are also excluded.
-		if i.Options == "ro" || strings.HasPrefix(i.Options, "ro,") {
+		if filter.exclude(i) {
+			slog.Debug("excluded mountpoint by config", "mountpoint", i.Mountpoint, "fstype", i.FSType)
 			return true, false
 		}
 
@@ -240,6 +397,15 @@ func getAllMountpoints() ([]string, error) {
 
 var mountinfo_Mounted = mountinfo.Mounted
 var EvalSymLinks = filepath.EvalSymlinks
+var osStat = os.Stat
+
+// Whether path itself is a mountpoint, symlinks are followed
+func IsMountpoint(path string) (bool, error) {
+	if path == string(os.PathSeparator) {
+		return true, nil
+	}
+	return mountinfo_Mounted(path)
+}
 
 // Obtain a mount point associated with a file.
 // Same as df <PATH>
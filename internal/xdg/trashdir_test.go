@@ -1,10 +1,18 @@
 package xdg
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
 	"slices"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/moby/sys/mountinfo"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/umlx5h/gtrash/internal/config"
 )
 
 func TestGetMountpoint(t *testing.T) {
@@ -66,3 +74,68 @@ func TestGetMountpoint(t *testing.T) {
 		require.Error(t, err, got)
 	})
 }
+
+func TestMountFilter(t *testing.T) {
+	f := newMountFilter(config.Mount{
+		IncludeFSTypes: []string{"tmpfs"},
+		ExcludeFSTypes: []string{"nfs"},
+		IncludePaths:   []string{"/mnt/nas/keep"},
+		ExcludePaths:   []string{"/mnt/nas/**"},
+	})
+
+	tests := []struct {
+		info    mountinfo.Info
+		include bool
+		exclude bool
+	}{
+		{info: mountinfo.Info{Mountpoint: "/", FSType: "ext4"}},
+		{info: mountinfo.Info{Mountpoint: "/tmp", FSType: "tmpfs"}, include: true},
+		{info: mountinfo.Info{Mountpoint: "/home", FSType: "nfs"}, exclude: true},
+		{info: mountinfo.Info{Mountpoint: "/mnt/nas/data", FSType: "cifs"}, exclude: true},
+		{info: mountinfo.Info{Mountpoint: "/mnt/nas/keep", FSType: "cifs"}, include: true, exclude: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.include, f.include(&tt.info), tt.info.Mountpoint)
+		assert.Equal(t, tt.exclude, f.exclude(&tt.info), tt.info.Mountpoint)
+	}
+}
+
+func TestScanExternalTrashDirsTimeout(t *testing.T) {
+	topDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(topDir, ".Trash-1000"), 0o700))
+
+	dirs, timedOut := scanExternalTrashDirsTimeout(topDir, "1000", time.Minute)
+	assert.False(t, timedOut)
+	require.Len(t, dirs, 1)
+	assert.Equal(t, filepath.Join(topDir, ".Trash-1000"), dirs[0].Dir)
+
+	dirs, timedOut = scanExternalTrashDirsTimeout(topDir, "1000", 0)
+	assert.False(t, timedOut, "no timeout")
+	assert.Len(t, dirs, 1)
+
+	// hung file system
+	block := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2) // .Trash/$uid and .Trash-$uid
+	osStat = func(name string) (fs.FileInfo, error) {
+		defer wg.Done()
+		<-block
+		return os.Stat(name)
+	}
+	t.Cleanup(func() {
+		close(block)
+		wg.Wait()
+		osStat = os.Stat
+	})
+
+	dirs, timedOut = scanExternalTrashDirsTimeout(topDir, "1000", 10*time.Millisecond)
+	assert.True(t, timedOut)
+	assert.Empty(t, dirs)
+
+	// not accessed again
+	start := time.Now()
+	_, timedOut = scanExternalTrashDirsTimeout(topDir, "1000", time.Minute)
+	assert.True(t, timedOut)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -16,6 +16,9 @@ import (
 const (
 	trashHeader = `[Trash Info]`
 	timeFormat  = "2006-01-02T15:04:05"
+
+	// Identifies one 'gtrash put' invocation, used to group files deleted together
+	KeySession = "X-GTrash-Session"
 )
 
 // XDG specifications
@@ -25,6 +28,25 @@ const (
 type Info struct {
 	Path         string    // $PWD/file.go (url decoded)
 	DeletionDate time.Time // 2023-01-01T00:00:00
+
+	// Other keys in [Trash Info] group (e.g. X-GTrash-Session)
+	// Preserved in the order of appearance so that they are written back as is.
+	Extra []InfoEntry
+}
+
+type InfoEntry struct {
+	Key   string
+	Value string
+}
+
+// Returns the value of the key in Extra
+func (i Info) Get(key string) (string, bool) {
+	for _, e := range i.Extra {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return "", false
 }
 
 func NewInfo(r io.Reader) (Info, error) {
@@ -48,10 +70,14 @@ func NewInfo(r io.Reader) (Info, error) {
 			// other group found, so exit
 			break
 		}
+		if len(line) > 0 && line[0] == '#' {
+			// comment
+			continue
+		}
 		if strings.Contains(line, "=") {
 			kv := strings.SplitN(line, "=", 2)
 
-			switch strings.TrimSpace(kv[0]) {
+			switch key := strings.TrimSpace(kv[0]); key {
 			case "Path":
 				if pathFound {
 					continue
@@ -72,6 +98,15 @@ func NewInfo(r io.Reader) (Info, error) {
 				}
 				info.DeletionDate = parsed
 				dateFound = true
+			default:
+				if !groupFound || key == "" {
+					continue
+				}
+				// first occurrence is used, same as Path and DeletionDate
+				if _, ok := info.Get(key); ok {
+					continue
+				}
+				info.Extra = append(info.Extra, InfoEntry{Key: key, Value: strings.TrimSpace(kv[1])})
 			}
 		}
 	}
@@ -89,7 +124,12 @@ func NewInfo(r io.Reader) (Info, error) {
 
 // represent INI format
 func (i Info) String() string {
-	return fmt.Sprintf("%s\nPath=%s\nDeletionDate=%s\n", trashHeader, queryEscapePath(i.Path), i.DeletionDate.Format(timeFormat))
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s\nPath=%s\nDeletionDate=%s\n", trashHeader, queryEscapePath(i.Path), i.DeletionDate.Format(timeFormat))
+	for _, e := range i.Extra {
+		fmt.Fprintf(&s, "%s=%s\n", e.Key, e.Value)
+	}
+	return s.String()
 }
 
 func (i Info) Save(trashDir TrashDir, filename string) (saveName string, deleteFn func() error, err error) {
@@ -140,6 +180,23 @@ func (i Info) Save(trashDir TrashDir, filename string) (saveName string, deleteF
 	return saveName, deleteFn, nil
 }
 
+// Save .trashinfo for the file already in the files folder as name
+// Unlike Save, fails if .trashinfo already exists instead of changing the name.
+func (i Info) SaveAs(trashDir TrashDir, name string) error {
+	f, err := os.OpenFile(filepath.Join(trashDir.InfoDir(), name+".trashinfo"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(i.String()); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("write failed: %w", err)
+	}
+
+	return nil
+}
+
 // Do not escape '/'
 // Escape ' ' as '%20', not '+'
 func queryEscapePath(s string) string {
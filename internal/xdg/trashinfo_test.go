@@ -1,7 +1,10 @@
 package xdg
 
 import (
+	"io/fs"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -65,6 +68,57 @@ Path=/notused
 	})
 }
 
+func TestInfoExtraKeys(t *testing.T) {
+	content := `[Trash Info]
+Path=/dummy
+DeletionDate=2023-01-01T00:00:00
+X-GTrash-Session=cm5abc
+X-Other = value with space
+X-Other=notused
+`
+	info, err := NewInfo(strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, []InfoEntry{
+		{Key: "X-GTrash-Session", Value: "cm5abc"},
+		{Key: "X-Other", Value: "value with space"},
+	}, info.Extra)
+
+	session, ok := info.Get(KeySession)
+	assert.True(t, ok)
+	assert.Equal(t, "cm5abc", session)
+
+	_, ok = info.Get("X-NotFound")
+	assert.False(t, ok)
+
+	t.Run("preserve unknown keys when writing", func(t *testing.T) {
+		assert.Equal(t, `[Trash Info]
+Path=/dummy
+DeletionDate=2023-01-01T00:00:00
+X-GTrash-Session=cm5abc
+X-Other=value with space
+`, info.String())
+	})
+}
+
+func TestInfoSaveAs(t *testing.T) {
+	trashDir := NewTrashDirManual(t.TempDir())
+	require.NoError(t, trashDir.CreateDir())
+
+	info := Info{
+		Path:         "/home/user/a b",
+		DeletionDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local),
+	}
+	require.NoError(t, info.SaveAs(trashDir, "a b"))
+
+	b, err := os.ReadFile(filepath.Join(trashDir.InfoDir(), "a b.trashinfo"))
+	require.NoError(t, err)
+	assert.Equal(t, info.String(), string(b))
+
+	// must not overwrite
+	assert.ErrorIs(t, info.SaveAs(trashDir, "a b"), fs.ErrExist)
+}
+
 func TestNewInfoError(t *testing.T) {
 	t.Run("detect_other_group", func(t *testing.T) {
 		_, err := NewInfo(strings.NewReader(`[Trash Info]
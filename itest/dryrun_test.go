@@ -0,0 +1,74 @@
+package itest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func checkFileExists(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("file not found. path=%q", path)
+	}
+}
+
+func checkFileNotExists(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Lstat(path); err == nil {
+		t.Errorf("file exists. path=%q", path)
+	}
+}
+
+// --dry-run must print the planned actions without moving or deleting anything.
+func TestDryRun(t *testing.T) {
+	cleanTrash(t)
+	defer cleanTrash(t)
+
+	f, err := os.CreateTemp("", "foo")
+	mustNoError(t, err)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	trashFilePath := filepath.Join(HOME_TRASH, "files", filepath.Base(f.Name()))
+	trashInfoPath := filepath.Join(HOME_TRASH, "info", filepath.Base(f.Name())+".trashinfo")
+
+	// put
+	out, err := exec.Command(execBinary, "put", "--dry-run", f.Name()).CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), "would trash \""+f.Name()+"\" to")
+	checkFileExists(t, f.Name())
+	checkFileNotExists(t, trashFilePath)
+
+	out, err = exec.Command(execBinary, "put", f.Name()).CombinedOutput()
+	mustNoError(t, err, string(out))
+	checkFileMoved(t, f.Name(), trashFilePath)
+
+	// restore
+	out, err = exec.Command(execBinary, "restore", "--dry-run", f.Name()).CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), "would restore \""+trashFilePath+"\" to \""+f.Name()+"\"")
+	assertContains(t, string(out), "Would restore 1/1 trashed files (dry-run)")
+	checkFileNotExists(t, f.Name())
+	checkFileExists(t, trashFilePath)
+	checkFileExists(t, trashInfoPath)
+
+	// rm
+	out, err = exec.Command(execBinary, "rm", "--dry-run", f.Name()).CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), f.Name())
+	assertContains(t, string(out), "Would remove 1 trashed files (dry-run)")
+	checkFileExists(t, trashFilePath)
+	checkFileExists(t, trashInfoPath)
+
+	// prune
+	out, err = exec.Command(execBinary, "prune", "--day", "0", "--dry-run").CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), f.Name())
+	assertContains(t, string(out), "Would remove 1 trashed files (dry-run)")
+	checkFileExists(t, trashFilePath)
+	checkFileExists(t, trashInfoPath)
+}
@@ -0,0 +1,58 @@
+package itest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// Files without .trashinfo in files/ can be adopted or deleted by metafix.
+func TestMetafixOrphanFiles(t *testing.T) {
+	cleanTrash(t)
+	defer cleanTrash(t)
+
+	filesDir := filepath.Join(HOME_TRASH, "files")
+	mustNoError(t, os.MkdirAll(filesDir, 0o700))
+	mustNoError(t, os.MkdirAll(filepath.Join(HOME_TRASH, "info"), 0o700))
+
+	adoptDir := t.TempDir()
+
+	// adopt
+	orphan := filepath.Join(filesDir, "orphan")
+	mustNoError(t, os.WriteFile(orphan, []byte("foo"), 0o644))
+
+	out, err := exec.Command(execBinary, "metafix", "--orphan-files", "adopt", "--adopt-dir", adoptDir).CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), "Found files without metadata: 1")
+	assertContains(t, string(out), "Adopted files without metadata: 1")
+
+	checkFileExists(t, orphan)
+	b, err := os.ReadFile(filepath.Join(HOME_TRASH, "info", "orphan.trashinfo"))
+	mustNoError(t, err)
+	assertContains(t, string(b), "[Trash Info]\n")
+	assertContains(t, string(b), "Path="+filepath.Join(adoptDir, "orphan")+"\n")
+	assertContains(t, string(b), "DeletionDate=")
+
+	// listed and restorable as a trashed file
+	out, err = exec.Command(execBinary, "find").CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), filepath.Join(adoptDir, "orphan"))
+
+	out, err = exec.Command(execBinary, "restore", filepath.Join(adoptDir, "orphan")).CombinedOutput()
+	mustNoError(t, err, string(out))
+	checkFileMoved(t, orphan, filepath.Join(adoptDir, "orphan"))
+
+	// delete
+	mustNoError(t, os.WriteFile(orphan, []byte("bar"), 0o644))
+
+	out, err = exec.Command(execBinary, "metafix", "--orphan-files", "delete").CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), "Deleted files without metadata: 1")
+	checkFileNotExists(t, orphan)
+	checkFileNotExists(t, filepath.Join(HOME_TRASH, "info", "orphan.trashinfo"))
+
+	out, err = exec.Command(execBinary, "metafix").CombinedOutput()
+	mustNoError(t, err, string(out))
+	assertContains(t, string(out), "do nothing: not found: trashed files")
+}
@@ -22,3 +22,19 @@ func TestSkipDotEndingPath(t *testing.T) {
 		})
 	}
 }
+
+// Protected paths must be refused without --i-know.
+// $ gtrash put ~
+// gtrash: cannot trash "/root": protected path (home directory or its parent), use --i-know to trash it anyway
+func TestRefuseProtectedPath(t *testing.T) {
+	paths := []string{"/", "/tmp", "/root", "/root/.local/share/Trash"}
+
+	for _, path := range paths {
+		t.Run(fmt.Sprintf("refused path %q", path), func(t *testing.T) {
+			cmd := exec.Command(execBinary, "put", path)
+			out, err := cmd.CombinedOutput()
+			mustError(t, err)
+			assertContains(t, string(out), "protected path")
+		})
+	}
+}